@@ -0,0 +1,109 @@
+package bruno
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/RobinCPel/graphql-postman/src/internal/postman"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// indent indents every line of a text with two spaces, which is how the content of a block in a .bru file is indented.
+func indent(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = "  " + l
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// block returns a block of a .bru file, e.g. "meta { ... }".
+func block(name, content string) string {
+	return name + " {\n" + indent(content) + "\n}\n"
+}
+
+// createRequest returns the .bru file of a GqlInput.
+func createRequest(entry postman.GqlInput, seq int, config postman.Config) (string, error) {
+	variables := entry.Variables
+	if variables == "" {
+		variables = "{}"
+	}
+
+	var prettyVariables bytes.Buffer
+	if err := json.Indent(&prettyVariables, []byte(variables), "", "  "); err != nil {
+		return "", errors.New(`the variables of "` + entry.Name + `" are not valid json: ` + err.Error())
+	}
+
+	auth := "none"
+	if config.Token != "" {
+		auth = "bearer"
+	}
+
+	var headers []string
+	for _, h := range config.Headers {
+		headers = append(headers, h.Key+": "+h.Value)
+	}
+	if config.OperationNameHeader != "" && entry.OperationName != "" {
+		headers = append(headers, config.OperationNameHeader+": "+entry.OperationName)
+	}
+
+	blocks := []string{
+		block("meta", "name: "+entry.Name+"\ntype: graphql\nseq: "+strconv.Itoa(seq)),
+		block("post", "url: {{baseUrl}}\nbody: graphql\nauth: "+auth),
+	}
+	if len(headers) > 0 {
+		blocks = append(blocks, block("headers", strings.Join(headers, "\n")))
+	}
+	if config.Token != "" {
+		blocks = append(blocks, block("auth:bearer", "token: {{token}}"))
+	}
+	blocks = append(blocks,
+		block("body:graphql", entry.Query),
+		block("body:graphql:vars", prettyVariables.String()),
+	)
+
+	return strings.Join(blocks, "\n"), nil
+}
+
+// CreateFiles returns the files of a Bruno collection, with the same folders, URL, and auth as the Postman
+// Collection that would be created with the same config. The URL is stored in the "Default" environment, and
+// the token is a secret of that environment, so Bruno does not store it in a file. The key of the returned
+// map is the path of the file, relative to the directory of the collection.
+func CreateFiles(gql []postman.GqlInput, config postman.Config) (map[string][]byte, error) {
+	collection, err := json.MarshalIndent(map[string]interface{}{
+		"version": "1",
+		"name":    config.Name,
+		"type":    "collection",
+		"ignore":  []string{"node_modules", ".git"},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	environment := block("vars", "baseUrl: "+config.URL)
+	if config.Token != "" {
+		environment += "\nvars:secret [\n  token\n]\n"
+	}
+
+	files := map[string][]byte{
+		"bruno.json":               append(collection, '\n'),
+		"environments/Default.bru": []byte(environment),
+	}
+
+	for _, folder := range postman.GroupInputs(gql, config.Folders) {
+		for i, entry := range folder.Inputs {
+			request, err := createRequest(entry, i+1, config)
+			if err != nil {
+				return nil, err
+			}
+			files[path.Join(folder.Name, entry.Name+".bru")] = []byte(request)
+		}
+	}
+
+	return files, nil
+}
@@ -0,0 +1,136 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/RobinCPel/graphql-postman/src/internal/graphql/introspection/reformatted"
+	"github.com/RobinCPel/graphql-postman/src/internal/postman"
+	log "github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Stdout is the output that makes a single file result be written to stdout.
+const Stdout = "-"
+
+// Input is what every exporter works from, the schema is only introspected and converted once per run.
+type Input struct {
+	Model   *reformatted.Model // The reformatted schema
+	Inputs  []postman.GqlInput // The operations, in the order in which they are exported
+	Skipped []string           // The keys of the operations that are in the schema, but could not be converted
+}
+
+// Options contains everything that can be configured about the exporters, every exporter only uses what applies to it.
+type Options struct {
+	ID                  string           // The ID of the Postman Collection
+	Name                string           // The name of the collection, document, or test plan
+	URL                 string           // The URL that the requests are sent to
+	Token               string           // The bearer token that the requests are sent with, optional
+	Headers             []postman.Header // Headers that every request is sent with
+	OperationNameHeader string           // The name of a header that every request sends its operation name in, optional
+	Folders             bool             // Whether the requests are grouped in a folder per operation type
+	BodyMode            string           // The body mode of the Postman Collection
+	SchemaVersion       string           // The schema version of the Postman Collection
+
+	Merge         string             // An existing Postman Collection to merge the result into, optional
+	Environment   string             // The file to write a matching Postman Environment to, optional
+	EnvironmentID string             // The ID of the Postman Environment
+	Variables     []postman.Variable // Extra variables of the Postman Environment
+
+	ScriptPerOperation bool   // Whether the curl and httpie formats write a script per operation
+	K6VUs              int    // The number of virtual users of the k6 script
+	K6Duration         string // How long the k6 script runs
+}
+
+// Exporter converts the operations to a certain format, and writes them to a file or a directory.
+//
+// This package is internal, so an exporter can only be added inside this module: implement it in a file of this
+// package, and Register it in an init function, like the built-in formats in formats.go. The -format flag, and the
+// config file, then know it by its name.
+type Exporter interface {
+	// DefaultOutput returns the file, or directory, that the result is written to when no output is given.
+	DefaultOutput() string
+
+	// Export writes the result to output.
+	Export(input Input, output string) error
+}
+
+// Factory creates an exporter with the given options.
+type Factory func(options Options) Exporter
+
+var factories = make(map[string]Factory)
+
+// Register makes a format available under a name, registering a name twice replaces the first factory.
+// It is meant to be called from the init functions of this package, see Exporter.
+func Register(name string, factory Factory) {
+	factories[name] = factory
+}
+
+// New creates the exporter of a format.
+func New(name string, options Options) (Exporter, error) {
+	factory, ok := factories[name]
+	if !ok {
+		return nil, errors.New(`format "` + name + `" does not exist`)
+	}
+
+	return factory(options), nil
+}
+
+// Names returns the names of all registered formats in alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// writeJSON writes a value as an indented json document.
+func writeJSON(output string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return writeFile(output, data, 0644)
+}
+
+// writeFile writes a single file, or writes it to stdout when output is Stdout.
+func writeFile(output string, data []byte, mode os.FileMode) error {
+	if output == Stdout {
+		log.Info("Writing the result to stdout...")
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	log.Info(`Writing the result to "` + output + `"...`)
+	return ioutil.WriteFile(output, data, mode)
+}
+
+// writeFiles writes files to a directory, the names of the files are relative paths with forward slashes.
+func writeFiles(output string, files map[string][]byte, mode os.FileMode) error {
+	if output == Stdout {
+		return errors.New("the result is a directory, it can not be written to stdout")
+	}
+
+	log.Info(`Writing the result to the directory "` + output + `"...`)
+	if err := os.MkdirAll(output, 0755); err != nil {
+		return err
+	}
+
+	for name, data := range files {
+		fileName := filepath.Join(output, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(fileName, data, mode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
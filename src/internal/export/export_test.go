@@ -0,0 +1,69 @@
+package export
+
+import (
+	"encoding/json"
+	"github.com/RobinCPel/graphql-postman/src/internal/postman"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteEnvironment(t *testing.T) {
+	for _, tt := range []struct {
+		format string
+		want   string // What the output refers to the URL with
+	}{
+		{FormatHar, "http://localhost/gql"},
+		{FormatPostman, "{{baseUrl}}"},
+	} {
+		t.Run(tt.format, func(t *testing.T) {
+			dir := t.TempDir()
+			options := Options{
+				URL:         "http://localhost/gql",
+				Token:       "secret",
+				Variables:   []postman.Variable{{Key: "user", Value: "1"}},
+				Environment: filepath.Join(dir, "env.json"),
+			}
+
+			exporter, err := New(tt.format, options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			output := filepath.Join(dir, "output.json")
+			input := Input{Inputs: []postman.GqlInput{{Name: "QueryUser", OperationType: "query", Query: "query QueryUser { user { __typename } }"}}}
+			if err = exporter.Export(input, output); err != nil {
+				t.Fatal(err)
+			}
+			if err = WriteEnvironment(options); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := ioutil.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(data), tt.want) {
+				t.Errorf("the output does not refer to the URL with %s:\n%s", tt.want, data)
+			}
+
+			data, err = ioutil.ReadFile(options.Environment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var env postman.Environment
+			if err = json.Unmarshal(data, &env); err != nil {
+				t.Fatal(err)
+			}
+			want := []postman.EnvironmentValue{
+				{Key: "baseUrl", Value: "http://localhost/gql", Type: "default", Enabled: true},
+				{Key: "token", Value: "secret", Type: "secret", Enabled: true},
+				{Key: "user", Value: "1", Type: "default", Enabled: true},
+			}
+			if !reflect.DeepEqual(env.Values, want) {
+				t.Errorf("got the variables %+v, want %+v", env.Values, want)
+			}
+		})
+	}
+}
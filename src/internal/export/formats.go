@@ -0,0 +1,293 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/RobinCPel/graphql-postman/src/internal/bruno"
+	"github.com/RobinCPel/graphql-postman/src/internal/graphqlfiles"
+	"github.com/RobinCPel/graphql-postman/src/internal/har"
+	"github.com/RobinCPel/graphql-postman/src/internal/insomnia"
+	"github.com/RobinCPel/graphql-postman/src/internal/jmeter"
+	"github.com/RobinCPel/graphql-postman/src/internal/k6"
+	"github.com/RobinCPel/graphql-postman/src/internal/openapi"
+	"github.com/RobinCPel/graphql-postman/src/internal/postman"
+	"github.com/RobinCPel/graphql-postman/src/internal/script"
+	log "github.com/sirupsen/logrus"
+	"io/ioutil"
+)
+
+// The names of the built-in formats.
+const (
+	FormatPostman  = "postman"
+	FormatHar      = "har"
+	FormatOpenAPI  = "openapi"
+	FormatGraphql  = "graphql-files"
+	FormatInsomnia = "insomnia"
+	FormatBruno    = "bruno"
+	FormatCurl     = "curl"
+	FormatHTTPie   = "httpie"
+	FormatK6       = "k6"
+	FormatJMeter   = "jmeter"
+)
+
+func init() {
+	Register(FormatPostman, func(o Options) Exporter { return postmanExporter{o} })
+	Register(FormatHar, func(o Options) Exporter { return harExporter{o} })
+	Register(FormatOpenAPI, func(o Options) Exporter { return openAPIExporter{o} })
+	Register(FormatGraphql, func(o Options) Exporter { return graphqlExporter{o} })
+	Register(FormatInsomnia, func(o Options) Exporter { return insomniaExporter{o} })
+	Register(FormatBruno, func(o Options) Exporter { return brunoExporter{o} })
+	Register(FormatCurl, func(o Options) Exporter { return scriptExporter{o, script.Curl} })
+	Register(FormatHTTPie, func(o Options) Exporter { return scriptExporter{o, script.HTTPie} })
+	Register(FormatK6, func(o Options) Exporter { return k6Exporter{o} })
+	Register(FormatJMeter, func(o Options) Exporter { return jmeterExporter{o} })
+}
+
+// postmanConfig returns the config of the Postman Collection, which the Insomnia and Bruno formats use as well.
+func (o Options) postmanConfig() postman.Config {
+	return postman.Config{
+		ID:       o.ID,
+		Name:     o.Name,
+		URL:      o.URL,
+		Token:    o.Token,
+		Headers:  o.Headers,
+		BodyMode: o.BodyMode,
+		Version:  o.SchemaVersion,
+		Folders:  o.Folders,
+
+		OperationNameHeader: o.OperationNameHeader,
+	}
+}
+
+type postmanExporter struct{ Options }
+
+func (e postmanExporter) DefaultOutput() string { return "api.postman_collection.json" }
+
+func (e postmanExporter) Export(input Input, output string) error {
+	log.Info("Storing the mutations and queries in a postman collection...")
+	config := e.postmanConfig()
+
+	// With an environment, the collection refers to the values in the environment, see WriteEnvironment
+	if e.Environment != "" {
+		config.URL = "{{baseUrl}}"
+		if e.Token != "" {
+			config.Token = "{{token}}"
+		}
+	}
+
+	col, err := postman.CreateCollection(input.Inputs, config)
+	if err != nil {
+		return errors.New("failed to create the postman collection: " + err.Error())
+	}
+
+	if e.Merge != "" {
+		log.Info(`Merging the result into "` + e.Merge + `"...`)
+		existingData, err := ioutil.ReadFile(e.Merge)
+		if err != nil {
+			return errors.New("failed to read the existing postman collection: " + err.Error())
+		}
+
+		merged, err := postman.Merge(existingData, *col, input.Skipped)
+		if err != nil {
+			return errors.New("failed to merge into the existing postman collection: " + err.Error())
+		}
+
+		return writeFile(output, merged, 0644)
+	}
+
+	return writeJSON(output, col)
+}
+
+// WriteEnvironment writes the Postman Environment to options.Environment. It is written once per run, whichever
+// formats are exported, and it has the values that the Postman Collection refers to.
+func WriteEnvironment(options Options) error {
+	variables := []postman.Variable{{Key: "baseUrl", Value: options.URL}}
+	if options.Token != "" {
+		variables = append(variables, postman.Variable{Key: "token", Value: options.Token, Secret: true})
+	}
+	variables = append(variables, options.Variables...)
+
+	env := postman.CreateEnvironment(variables, options.EnvironmentID, options.Name)
+	data, err := json.MarshalIndent(env, "", "    ")
+	if err != nil {
+		return errors.New("failed to encode the postman environment as json: " + err.Error())
+	}
+
+	log.Info(`Writing the environment to "` + options.Environment + `"...`)
+	if err = ioutil.WriteFile(options.Environment, data, 0644); err != nil {
+		return errors.New("failed to write the postman environment to a file: " + err.Error())
+	}
+
+	return nil
+}
+
+type harExporter struct{ Options }
+
+func (e harExporter) DefaultOutput() string { return "api.har" }
+
+func (e harExporter) Export(input Input, output string) error {
+	log.Info("Storing the mutations and queries in a HAR document...")
+	config := har.Config{
+		URL:                 e.URL,
+		OperationNameHeader: e.OperationNameHeader,
+	}
+	if e.Token != "" {
+		config.Headers = append(config.Headers, har.NameValue{Name: "Authorization", Value: "Bearer " + e.Token})
+	}
+	for _, h := range e.Headers {
+		config.Headers = append(config.Headers, har.NameValue{Name: h.Key, Value: h.Value})
+	}
+
+	doc, err := har.CreateHar(input.Inputs, config)
+	if err != nil {
+		return errors.New("failed to create the HAR document: " + err.Error())
+	}
+
+	return writeJSON(output, doc)
+}
+
+type openAPIExporter struct{ Options }
+
+func (e openAPIExporter) DefaultOutput() string { return "api.openapi.json" }
+
+func (e openAPIExporter) Export(input Input, output string) error {
+	log.Info("Storing the mutations and queries in an OpenAPI document...")
+	config := openapi.Config{
+		Title:               e.Name,
+		URL:                 e.URL,
+		Bearer:              e.Token != "",
+		Headers:             make(map[string]string),
+		OperationNameHeader: e.OperationNameHeader,
+	}
+	for _, h := range e.Headers {
+		config.Headers[h.Key] = h.Value
+	}
+
+	doc, err := openapi.CreateDocument(input.Inputs, input.Model.Types, config)
+	if err != nil {
+		return errors.New("failed to create the OpenAPI document: " + err.Error())
+	}
+
+	return writeJSON(output, doc)
+}
+
+type graphqlExporter struct{ Options }
+
+func (e graphqlExporter) DefaultOutput() string { return "operations" }
+
+func (e graphqlExporter) Export(input Input, output string) error {
+	log.Info("Storing the mutations and queries in .graphql files...")
+	files, err := graphqlfiles.CreateFiles(input.Inputs)
+	if err != nil {
+		return errors.New("failed to create the .graphql files: " + err.Error())
+	}
+
+	return writeFiles(output, files, 0644)
+}
+
+type insomniaExporter struct{ Options }
+
+func (e insomniaExporter) DefaultOutput() string { return "api.insomnia.json" }
+
+func (e insomniaExporter) Export(input Input, output string) error {
+	log.Info("Storing the mutations and queries in an Insomnia export...")
+	export, err := insomnia.CreateExport(input.Inputs, e.postmanConfig())
+	if err != nil {
+		return errors.New("failed to create the Insomnia export: " + err.Error())
+	}
+
+	return writeJSON(output, export)
+}
+
+type brunoExporter struct{ Options }
+
+func (e brunoExporter) DefaultOutput() string { return "bruno" }
+
+func (e brunoExporter) Export(input Input, output string) error {
+	log.Info("Storing the mutations and queries in a Bruno collection...")
+	files, err := bruno.CreateFiles(input.Inputs, e.postmanConfig())
+	if err != nil {
+		return errors.New("failed to create the Bruno collection: " + err.Error())
+	}
+
+	return writeFiles(output, files, 0644)
+}
+
+type scriptExporter struct {
+	Options
+	Tool string // Either script.Curl or script.HTTPie
+}
+
+// DefaultOutput is a directory named after the tool with -script-per-operation, and a single script otherwise.
+func (e scriptExporter) DefaultOutput() string {
+	if e.ScriptPerOperation {
+		return e.Tool
+	}
+	return "api." + e.Tool + ".sh"
+}
+
+func (e scriptExporter) Export(input Input, output string) error {
+	log.Info("Storing the mutations and queries in " + e.Tool + " scripts...")
+	config := script.Config{
+		Tool:                e.Tool,
+		URL:                 e.URL,
+		Token:               e.Token,
+		Headers:             e.Headers,
+		OperationNameHeader: e.OperationNameHeader,
+	}
+
+	if e.ScriptPerOperation {
+		files, err := script.CreateScripts(input.Inputs, config)
+		if err != nil {
+			return errors.New("failed to create the " + e.Tool + " scripts: " + err.Error())
+		}
+		return writeFiles(output, files, 0755)
+	}
+
+	data, err := script.CreateScript(input.Inputs, config)
+	if err != nil {
+		return errors.New("failed to create the " + e.Tool + " script: " + err.Error())
+	}
+	return writeFile(output, data, 0755)
+}
+
+type k6Exporter struct{ Options }
+
+func (e k6Exporter) DefaultOutput() string { return "api.k6.js" }
+
+func (e k6Exporter) Export(input Input, output string) error {
+	log.Info("Storing the mutations and queries in a k6 script...")
+	data, err := k6.CreateScript(input.Inputs, k6.Config{
+		URL:                 e.URL,
+		Token:               e.Token,
+		VUs:                 e.K6VUs,
+		Duration:            e.K6Duration,
+		Headers:             e.Headers,
+		OperationNameHeader: e.OperationNameHeader,
+	})
+	if err != nil {
+		return errors.New("failed to create the k6 script: " + err.Error())
+	}
+
+	return writeFile(output, data, 0644)
+}
+
+type jmeterExporter struct{ Options }
+
+func (e jmeterExporter) DefaultOutput() string { return "api.jmx" }
+
+func (e jmeterExporter) Export(input Input, output string) error {
+	log.Info("Storing the mutations and queries in a JMeter test plan...")
+	data, err := jmeter.CreatePlan(input.Inputs, jmeter.Config{
+		Name:                e.Name,
+		URL:                 e.URL,
+		Token:               e.Token,
+		Headers:             e.Headers,
+		OperationNameHeader: e.OperationNameHeader,
+	})
+	if err != nil {
+		return errors.New("failed to create the JMeter test plan: " + err.Error())
+	}
+
+	return writeFile(output, data, 0644)
+}
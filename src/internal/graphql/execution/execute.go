@@ -0,0 +1,45 @@
+package execution
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// Response is the response of a GraphQL endpoint to a request.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Execute sends a GraphQL request to an endpoint with a client and returns the response, whatever its status is.
+// The payload is the JSON body of the request, see postman.GqlInput.Payload. The request, including reading the
+// response, is canceled when the context is done, so give it a deadline, since the client has none.
+func Execute(ctx context.Context, client *http.Client, url, payload string, header http.Header) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	for k, values := range header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	r, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{Status: r.StatusCode, Header: r.Header, Body: body}, nil
+}
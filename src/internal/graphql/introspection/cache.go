@@ -0,0 +1,83 @@
+package introspection
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// cacheEntry is an introspection result, as it is stored in the cache directory.
+type cacheEntry struct {
+	Endpoint  string    `json:"endpoint"`
+	FetchedAt time.Time `json:"fetchedAt"`
+	ETag      string    `json:"etag,omitempty"` // The ETag the endpoint responded with, to ask whether the result has changed
+	Model     *Model    `json:"model"`
+}
+
+// cacheFile returns the path of the cache file of an endpoint.
+// The headers are part of the key, so that different credentials never share a schema, but only as a hash,
+// so that no tokens end up on the disk. The depth is part of the key as well, because it changes the result.
+func cacheFile(endpoint string, depth int, options Options) string {
+	names := make([]string, 0, len(options.Header))
+	for name := range options.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	h.Write([]byte(endpoint + "\n" + strconv.Itoa(depth) + "\n"))
+	for _, name := range names {
+		for _, v := range options.Header[name] {
+			h.Write([]byte(name + ": " + v + "\n"))
+		}
+	}
+
+	return filepath.Join(options.CacheDir, hex.EncodeToString(h.Sum(nil))+".json")
+}
+
+// readCache reads a cache entry, it returns nil without an error when there is none.
+func readCache(path string) (*cacheEntry, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entry cacheEntry
+	if err = json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	if entry.Model == nil {
+		return nil, nil
+	}
+
+	return &entry, nil
+}
+
+// writeCache writes a cache entry. Only the owner can read it, because a schema that requires authentication is private.
+func writeCache(path string, entry cacheEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that concurrent runs never read half an entry
+	tmp := path + ".tmp" + strconv.Itoa(os.Getpid())
+	if err = ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,116 @@
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Capabilities contains the introspection fields that a server supports, next to the ones that every server has.
+type Capabilities struct {
+	SpecifiedByURL        string // The name of the field with the specification URL of a scalar, "specifiedByURL" or the older "specifiedByUrl", empty when it is not supported
+	IsOneOf               bool   // Whether __Type has isOneOf, for input objects of which exactly one field has to be set
+	IsRepeatable          bool   // Whether __Directive has isRepeatable
+	DeprecatedArgs        bool   // Whether __Field.args accepts includeDeprecated, and __InputValue has isDeprecated
+	DeprecatedInputFields bool   // Whether __Type.inputFields accepts includeDeprecated, and __InputValue has isDeprecated
+}
+
+// capabilitiesQuery asks for the fields of the introspection types themselves.
+var capabilitiesQuery = document{
+	Query: `query IntrospectionCapabilities {
+  type: __type(name: "__Type") {
+    ...MetaFields
+  }
+  field: __type(name: "__Field") {
+    ...MetaFields
+  }
+  inputValue: __type(name: "__InputValue") {
+    ...MetaFields
+  }
+  directive: __type(name: "__Directive") {
+    ...MetaFields
+  }
+}
+
+fragment MetaFields on __Type {
+  fields {
+    name
+    args {
+      name
+    }
+  }
+}
+`,
+	OperationName: "IntrospectionCapabilities",
+}
+
+// metaType is an introspection type, as it is returned by the capabilities query.
+type metaType struct {
+	Fields []struct {
+		Named
+		Args []Named `json:"args"`
+	} `json:"fields"`
+}
+
+// has returns whether the type has a field, and, when arg is not empty, whether that field has the argument.
+func (t *metaType) has(field, arg string) bool {
+	if t == nil {
+		return false
+	}
+
+	for _, f := range t.Fields {
+		if f.Name != field {
+			continue
+		}
+		if arg == "" {
+			return true
+		}
+		for _, a := range f.Args {
+			if a.Name == arg {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// probe detects the capabilities of the server, a *ResponseError is returned when the server does not allow the query.
+func probe(ctx context.Context, endpoint, transport string, options Options) (Capabilities, error) {
+	var capabilities Capabilities
+
+	body, _, err := send(ctx, endpoint, transport, capabilitiesQuery, options)
+	if err != nil {
+		return capabilities, err
+	}
+
+	var response struct {
+		Errors []Error `json:"errors"`
+		Data   struct {
+			Type       *metaType `json:"type"`
+			Field      *metaType `json:"field"`
+			InputValue *metaType `json:"inputValue"`
+			Directive  *metaType `json:"directive"`
+		} `json:"data"`
+	}
+	if err = json.Unmarshal(body, &response); err != nil {
+		return capabilities, err
+	}
+	if len(response.Errors) > 0 {
+		return capabilities, &ResponseError{Errors: response.Errors}
+	}
+
+	data := response.Data
+	if data.Type.has("specifiedByURL", "") {
+		capabilities.SpecifiedByURL = "specifiedByURL"
+	} else if data.Type.has("specifiedByUrl", "") {
+		capabilities.SpecifiedByURL = "specifiedByUrl"
+	}
+	capabilities.IsOneOf = data.Type.has("isOneOf", "")
+	capabilities.IsRepeatable = data.Directive.has("isRepeatable", "")
+
+	isDeprecated := data.InputValue.has("isDeprecated", "")
+	capabilities.DeprecatedArgs = isDeprecated && data.Field.has("args", "includeDeprecated")
+	capabilities.DeprecatedInputFields = isDeprecated && data.Type.has("inputFields", "includeDeprecated")
+
+	return capabilities, nil
+}
@@ -0,0 +1,18 @@
+package introspection
+
+import "strings"
+
+// ResponseError is returned when the introspection result has errors in it.
+type ResponseError struct {
+	Errors  []Error // The errors, as the server returned them
+	Partial bool    // Whether there is a schema in the result as well, which may be incomplete
+}
+
+func (e *ResponseError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Message
+	}
+
+	return "the introspection result has errors: " + strings.Join(messages, "; ")
+}
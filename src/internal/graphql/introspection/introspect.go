@@ -1,31 +1,307 @@
 package introspection
 
 import (
+	"bytes"
+	"context"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
+	log "github.com/sirupsen/logrus"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
-	"strings"
+	"strconv"
+	"syscall"
+	"time"
 )
 
-const request = `{"operationName":"IntrospectionQuery","variables":{},"query":"query IntrospectionQuery {\n  __schema {\n    queryType {\n        name\n    }\n    mutationType {\n        name\n    }\n    types {\n      name\n      fields(includeDeprecated: false) {\n        name\n        args {\n          name\n          type {\n            ...TypeRef\n          }\n        }\n        type {\n          ...TypeRef\n        }\n      }\n      inputFields {\n        name\n        type {\n          ...TypeRef\n        }\n      }\n      enumValues(includeDeprecated: false) {\n        name\n      }\n      possibleTypes {\n        ...TypeRef\n      }\n    }\n  }\n}\n\nfragment TypeRef on __Type {\n  kind\n  name\n  ofType {\n    kind\n    name\n    ofType {\n      kind\n      name\n      ofType {\n        kind\n        name\n        ofType {\n          kind\n          name\n          ofType {\n            kind\n            name\n            ofType {\n              kind\n              name\n              ofType {\n                kind\n                name\n              }\n            }\n          }\n        }\n      }\n    }\n  }\n}\n"}`
+// Options contains everything that can be configured about introspecting an endpoint.
+type Options struct {
+	Client      *http.Client  // The client that sends the introspection query, http.DefaultClient when it is nil
+	Transport   string        // How the introspection query is sent, TransportPost when it is empty
+	Header      http.Header   // Headers that the introspection query is sent with, like the Authorization header
+	Timeout     time.Duration // How long a single attempt may take, no limit when it is zero
+	Retries     int           // How many times a failed attempt is retried
+	RetryDelay  time.Duration // How long to wait before the first retry, it doubles for every next retry
+	WaitUntilUp time.Duration // Keep retrying for this long, no matter the number of retries, for endpoints that are starting up
+	Depth       int           // The number of levels of type references to ask for, DefaultTypeRefDepth when it is zero
+	CacheDir    string        // The directory to cache introspection results in, no caching when it is empty
+	CacheTTL    time.Duration // How long a cached result is used without asking the endpoint, it is always revalidated when it is zero
+	Refresh     bool          // Whether the cached result is ignored, and replaced by a new one
+}
+
+// ErrNotModified is returned by send when the endpoint responds with a 304 (Not Modified) status,
+// because the result matches the ETag in the If-None-Match header.
+var ErrNotModified = errors.New("the introspection result has not been modified")
+
+// maxRetryDelay is the longest that is backed off between two attempts, a Retry-After header can ask for longer.
+const maxRetryDelay = 30 * time.Second
+
+// statusError is returned when the endpoint responds with a status other than 200 (OK), and without GraphQL errors.
+type statusError struct {
+	StatusCode int
+	RetryAfter time.Duration // Zero when the response has no Retry-After header
+}
+
+func (e *statusError) Error() string {
+	return "response status is " + strconv.Itoa(e.StatusCode) + ", not 200 (OK)"
+}
+
+// retryable returns whether an attempt that failed with err may succeed when it is tried again,
+// that is when the endpoint could not be reached, or is overloaded or broken for the moment.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+
+	// A certificate that can not be verified will not be verified the next time either
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &unknownAuthorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return false
+	}
+
+	// Timeouts, and connections that could not be made, or that were broken off. Other errors of the transport, like an
+	// unsupported protocol scheme or an invalid url, will be the same the next time, so those are not retried.
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// parseRetryAfter parses the Retry-After header, which is either a number of seconds or a date.
+func parseRetryAfter(value string) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+
+	return 0
+}
 
 // Introspect introspects a graphql endpoint and returns the result in structs.
-func Introspect(url string) (*Model, error) {
-	r, err := http.Post(url, "application/json", strings.NewReader(request))
+// When the endpoint responds with a 405 or 415 status, the query is sent with another transport.
+// Attempts that fail because of the connection, or because of a 5xx or 429 status, are retried with an exponential backoff.
+//
+// When the result has errors, a *ResponseError is returned. If the result has a schema as well,
+// the model is returned next to the error, so that the caller can decide whether a partial schema is good enough.
+//
+// With a CacheDir, a cached result that is younger than the CacheTTL is returned without asking the endpoint.
+// An older one is revalidated with its ETag, if the endpoint sent one. Only complete results are cached.
+func Introspect(ctx context.Context, endpoint string, options Options) (*Model, error) {
+	if options.CacheDir == "" {
+		model, _, err := introspect(ctx, endpoint, "", options)
+		return model, err
+	}
+
+	depth := options.Depth
+	if depth == 0 {
+		depth = DefaultTypeRefDepth
+	}
+	path := cacheFile(endpoint, depth, options)
+
+	var cached *cacheEntry
+	if !options.Refresh {
+		var err error
+		if cached, err = readCache(path); err != nil {
+			log.WithError(err).Warning("failed to read the cached introspection result")
+		}
+	}
+
+	etag := ""
+	if cached != nil {
+		if time.Since(cached.FetchedAt) < options.CacheTTL {
+			log.Info("Using the cached introspection result of " + cached.FetchedAt.Format(time.RFC3339) + "...")
+			return cached.Model, nil
+		}
+		etag = cached.ETag
+	}
+
+	model, newETag, err := introspect(ctx, endpoint, etag, options)
+	if errors.Is(err, ErrNotModified) {
+		log.Info("The introspection result has not been modified, using the cached one...")
+		model, newETag, err = cached.Model, etag, nil
+	}
 	if err != nil {
-		return nil, err
+		return model, err
 	}
 
-	if r.StatusCode != http.StatusOK {
-		return nil, errors.New("response status is not 200 (OK)")
+	entry := cacheEntry{Endpoint: endpoint, FetchedAt: time.Now(), ETag: newETag, Model: model}
+	if err = writeCache(path, entry); err != nil {
+		log.WithError(err).Warning("failed to cache the introspection result")
+	}
+
+	return model, nil
+}
+
+// introspect introspects a graphql endpoint, with retries and fallbacks, and returns the result with its ETag.
+// When etag is not empty, ErrNotModified is returned if the result still matches it.
+func introspect(ctx context.Context, endpoint, etag string, options Options) (*Model, string, error) {
+	deadline := time.Now().Add(options.WaitUntilUp)
+	delay := options.RetryDelay
+	transport := options.Transport
+	if transport == "" {
+		transport = TransportPost
 	}
-	if r.Body == nil {
-		return nil, errors.New("response body is empty")
+	if _, ok := fallbacks[transport]; !ok {
+		return nil, "", errors.New(`transport "` + transport + `" does not exist`)
 	}
+	tried := map[string]bool{transport: true}
+
+	for attempt := 0; ; attempt++ {
+		model, capabilities, newETag, err := introspectOnce(ctx, endpoint, transport, etag, options)
+
+		// Fall back to another transport right away when the endpoint does not support this one
+		var statusErr *statusError
+		if errors.As(err, &statusErr) {
+			if next, ok := fallbacks[transport][statusErr.StatusCode]; ok && !tried[next] {
+				log.WithError(err).Info(`Introspection failed, falling back to the "` + next + `" transport...`)
+				transport = next
+				tried[next] = true
+				attempt--
+				continue
+			}
+		}
+
+		if err == nil || !retryable(ctx, err) {
+			if model != nil {
+				resolveTruncated(ctx, endpoint, transport, capabilities, options, model)
+			}
+			return model, newETag, err
+		}
+		if attempt >= options.Retries && time.Now().After(deadline) {
+			return nil, "", err
+		}
+
+		// Wait as long as the server asks, or else back off exponentially
+		wait := delay
+		if wait > maxRetryDelay {
+			wait = maxRetryDelay
+		}
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+			wait = statusErr.RetryAfter
+		}
+		delay *= 2
+
+		log.WithError(err).Info("Introspection failed, retrying in " + wait.String() + "...")
+		select {
+		case <-ctx.Done():
+			return nil, "", ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// introspectOnce detects the capabilities of the server, and sends the richest introspection query that it supports
+// a single time, with a transport. The ETag of the response is returned next to the model.
+func introspectOnce(ctx context.Context, endpoint, transport, etag string, options Options) (*Model, Capabilities, string, error) {
+	depth := options.Depth
+	if depth == 0 {
+		depth = DefaultTypeRefDepth
+	}
+
+	capabilities, err := probe(ctx, endpoint, transport, options)
+	if err != nil {
+		// Errors that the introspection query would run into as well are handled by the caller
+		var statusErr *statusError
+		if retryable(ctx, err) || errors.As(err, &statusErr) && fallbacks[transport][statusErr.StatusCode] != "" {
+			return nil, capabilities, "", err
+		}
+		log.WithError(err).Info("Could not detect the introspection capabilities, using the basic introspection query...")
+	}
+
+	// Only the introspection query itself is conditional, the other queries are too small to bother
+	queryOptions := options
+	if etag != "" {
+		queryOptions.Header = options.Header.Clone()
+		if queryOptions.Header == nil {
+			queryOptions.Header = http.Header{}
+		}
+		queryOptions.Header.Set("If-None-Match", etag)
+	}
+
+	body, header, err := send(ctx, endpoint, transport, buildQuery(depth, capabilities), queryOptions)
+	if err != nil {
+		return nil, capabilities, "", err
+	}
+
+	model, err := Decode(bytes.NewReader(body))
+	return model, capabilities, header.Get("ETag"), err
+}
+
+// send sends a GraphQL request with a transport, and returns the body and the headers of the response when its status is 200 (OK).
+func send(ctx context.Context, endpoint, transport string, doc document, options Options) ([]byte, http.Header, error) {
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	req, err := newRequest(ctx, endpoint, transport, doc, options.Header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client := options.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	r, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Body.Close()
 
-	// Convert the data
 	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if r.StatusCode == http.StatusNotModified {
+		return nil, r.Header, ErrNotModified
+	}
+
+	// The transport is not supported, whatever the body says
+	if _, ok := fallbacks[transport][r.StatusCode]; ok {
+		return nil, nil, &statusError{StatusCode: r.StatusCode}
+	}
+
+	if r.StatusCode != http.StatusOK {
+		// Servers that have introspection disabled often explain that in the errors of the response
+		var response struct {
+			Errors []Error `json:"errors"`
+		}
+		if json.Unmarshal(body, &response) == nil && len(response.Errors) > 0 {
+			return nil, nil, &ResponseError{Errors: response.Errors}
+		}
+		return nil, nil, &statusError{StatusCode: r.StatusCode, RetryAfter: parseRetryAfter(r.Header.Get("Retry-After"))}
+	}
+
+	return body, r.Header, nil
+}
+
+// Decode reads an introspection result, either the whole response to the introspection query,
+// or only the "data" in it, like {"__schema": {...}}.
+//
+// Like Introspect, it returns the model next to a *ResponseError when the result has both errors and a schema.
+func Decode(r io.Reader) (*Model, error) {
+	body, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
@@ -35,5 +311,23 @@ func Introspect(url string) (*Model, error) {
 		return nil, err
 	}
 
+	// Not a whole response, try reading it as the data
+	if model.Data.Schema.Types == nil && len(model.Errors) == 0 {
+		if err = json.Unmarshal(body, &model.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(model.Errors) > 0 {
+		responseErr := &ResponseError{Errors: model.Errors, Partial: model.Data.Schema.Types != nil}
+		if responseErr.Partial {
+			return &model, responseErr
+		}
+		return nil, responseErr
+	}
+	if model.Data.Schema.Types == nil {
+		return nil, errors.New("the introspection result has no schema")
+	}
+
 	return &model, nil
 }
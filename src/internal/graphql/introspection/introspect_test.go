@@ -0,0 +1,53 @@
+package introspection
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryable(t *testing.T) {
+	// A port that nothing listens on, to get a refused connection
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := "http://" + listener.Addr().String()
+	listener.Close()
+
+	for _, tt := range []struct {
+		name     string
+		endpoint string
+		err      error // Used instead of the error of a request to the endpoint
+		want     bool
+	}{
+		{name: "refused connection", endpoint: closed, want: true},
+		{name: "unsupported protocol scheme", endpoint: "htp://localhost/gql", want: false},
+		{name: "no host", endpoint: "http:///gql", want: false},
+		{name: "timeout", err: &net.OpError{Op: "read", Err: context.DeadlineExceeded}, want: true},
+		{name: "503", err: &statusError{StatusCode: http.StatusServiceUnavailable}, want: true},
+		{name: "429", err: &statusError{StatusCode: http.StatusTooManyRequests}, want: true},
+		{name: "400", err: &statusError{StatusCode: http.StatusBadRequest}, want: false},
+		{name: "GraphQL errors", err: &ResponseError{Errors: []Error{{Message: "introspection is disabled"}}}, want: false},
+		{name: "other", err: errors.New("invalid character"), want: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.err
+			if err == nil {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				_, _, err = send(ctx, tt.endpoint, TransportPost, document{Query: "{ __typename }"}, Options{})
+				if err == nil {
+					t.Fatal("the request did not fail")
+				}
+			}
+
+			if got := retryable(context.Background(), err); got != tt.want {
+				t.Errorf("retryable(%v) = %v, want %v", err, got, tt.want)
+			}
+		})
+	}
+}
@@ -36,7 +36,8 @@ type TypeRef struct {
 
 type NamedTypeRef struct {
 	Named
-	Type TypeRef `json:"type"`
+	Type         TypeRef `json:"type"`
+	IsDeprecated bool    `json:"isDeprecated"` // Always false when the server can not return deprecated arguments and input fields
 }
 
 type TypeField struct {
@@ -52,19 +53,44 @@ type TypeField struct {
 // table that describes which fields are filled for each type.
 type Type struct {
 	Named
-	Fields        []TypeField    `json:"fields"`
-	InputFields   []NamedTypeRef `json:"inputFields"`
-	EnumValues    []Named        `json:"enumValues"`
-	PossibleTypes []TypeRef      `json:"possibleTypes"`
+	SpecifiedByURL *string        `json:"specifiedByURL"` // Only for scalars, when the server supports it
+	IsOneOf        bool           `json:"isOneOf"`        // Only for input objects, when the server supports it
+	Fields         []TypeField    `json:"fields"`
+	InputFields    []NamedTypeRef `json:"inputFields"`
+	EnumValues     []Named        `json:"enumValues"`
+	PossibleTypes  []TypeRef      `json:"possibleTypes"`
+}
+
+// Directive is a directive that the schema supports.
+type Directive struct {
+	Named
+	Locations    []string `json:"locations"`
+	IsRepeatable bool     `json:"isRepeatable"` // Always false when the server does not support it
+}
+
+// Location is a place in the introspection query that an error is about.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error is one of the errors in a GraphQL response.
+type Error struct {
+	Message    string                 `json:"message"`
+	Locations  []Location             `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`       // The names of the fields, and the indexes of the list items
+	Extensions map[string]interface{} `json:"extensions,omitempty"` // Anything the server adds, like an error code
 }
 
 // Model encapsulates all of the data that is returned from a GraphQL introspection query.
 type Model struct {
-	Data struct {
+	Errors []Error `json:"errors,omitempty"`
+	Data   struct {
 		Schema struct {
-			QueryType    Named  `json:"queryType"`    // Contains the name of the type that contains all queries
-			MutationType Named  `json:"mutationType"` // Contains the name of the type that contains all mutations
-			Types        []Type `json:"types"`
+			QueryType    Named       `json:"queryType"`    // Contains the name of the type that contains all queries
+			MutationType Named       `json:"mutationType"` // Contains the name of the type that contains all mutations
+			Types        []Type      `json:"types"`
+			Directives   []Directive `json:"directives"`
 		} `json:"__schema"`
 	} `json:"data"`
 }
@@ -0,0 +1,144 @@
+package introspection
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// DefaultTypeRefDepth is the number of levels of type references that the introspection query asks for by default,
+// which is enough for a non-null list of non-null lists of non-null types, with room to spare.
+const DefaultTypeRefDepth = 8
+
+// document is a GraphQL request that can be sent to the endpoint.
+type document struct {
+	Query         string
+	OperationName string
+}
+
+// queryWriter writes a GraphQL document, indenting every line by the number of braces that are open.
+type queryWriter struct {
+	strings.Builder
+	level int
+}
+
+func (w *queryWriter) line(s string) {
+	if strings.HasPrefix(s, "}") {
+		w.level--
+	}
+	w.WriteString(strings.Repeat("  ", w.level) + s + "\n")
+	if strings.HasSuffix(s, "{") {
+		w.level++
+	}
+}
+
+// typeSelection writes the selection set of a __Type, which is the same for every type that is introspected.
+// The fields that not every server supports are only added when the server has the capability.
+func (w *queryWriter) typeSelection(c Capabilities) {
+	w.line("name")
+	if c.SpecifiedByURL == "specifiedByURL" {
+		w.line("specifiedByURL")
+	} else if c.SpecifiedByURL != "" {
+		w.line("specifiedByURL: " + c.SpecifiedByURL)
+	}
+	if c.IsOneOf {
+		w.line("isOneOf")
+	}
+	w.line("fields(includeDeprecated: false) {")
+	w.line("name")
+	w.inputValues("args", c.DeprecatedArgs)
+	w.line("type {")
+	w.line("...TypeRef")
+	w.line("}")
+	w.line("}")
+	w.inputValues("inputFields", c.DeprecatedInputFields)
+	w.line("enumValues(includeDeprecated: false) {")
+	w.line("name")
+	w.line("}")
+	w.line("possibleTypes {")
+	w.line("...TypeRef")
+	w.line("}")
+}
+
+// inputValues writes the selection of the arguments or the input fields, including the deprecated ones when
+// the server supports that, so that the required ones can be sent, and the others recognized by their isDeprecated.
+func (w *queryWriter) inputValues(field string, deprecated bool) {
+	if deprecated {
+		w.line(field + "(includeDeprecated: true) {")
+		w.line("name")
+		w.line("isDeprecated")
+	} else {
+		w.line(field + " {")
+		w.line("name")
+	}
+	w.line("type {")
+	w.line("...TypeRef")
+	w.line("}")
+	w.line("}")
+}
+
+// typeRefFragment writes the TypeRef fragment, with depth levels of type references.
+func (w *queryWriter) typeRefFragment(depth int) {
+	if depth < 1 {
+		depth = 1
+	}
+
+	w.line("fragment TypeRef on __Type {")
+	for i := 0; i < depth; i++ {
+		if i > 0 {
+			w.line("ofType {")
+		}
+		w.line("kind")
+		w.line("name")
+	}
+	for i := 0; i < depth; i++ {
+		w.line("}")
+	}
+}
+
+// buildQuery builds the introspection query, with depth levels of type references, and with the capabilities of the server.
+func buildQuery(depth int, c Capabilities) document {
+	var w queryWriter
+	w.line("query IntrospectionQuery {")
+	w.line("__schema {")
+	w.line("queryType {")
+	w.line("name")
+	w.line("}")
+	w.line("mutationType {")
+	w.line("name")
+	w.line("}")
+	w.line("types {")
+	w.typeSelection(c)
+	w.line("}")
+	w.line("directives {")
+	w.line("name")
+	w.line("locations")
+	if c.IsRepeatable {
+		w.line("isRepeatable")
+	}
+	w.line("}")
+	w.line("}")
+	w.line("}")
+	w.WriteString("\n")
+	w.typeRefFragment(depth)
+
+	return document{Query: w.String(), OperationName: "IntrospectionQuery"}
+}
+
+// buildTypesQuery builds a query that introspects the types with the given names, like buildQuery does.
+// The type with names[i] is in the field "t<i>" of the data.
+func buildTypesQuery(names []string, depth int, c Capabilities) document {
+	var w queryWriter
+	w.line("query IntrospectionTypes {")
+	for i, name := range names {
+		quoted, _ := json.Marshal(name)
+		w.line("t" + strconv.Itoa(i) + ": __type(name: " + string(quoted) + ") {")
+		w.typeSelection(c)
+		w.line("}")
+	}
+	w.line("}")
+	w.WriteString("\n")
+	w.typeRefFragment(depth)
+
+	return document{Query: w.String(), OperationName: "IntrospectionTypes"}
+}
@@ -0,0 +1,755 @@
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecoveryOptions contains everything that can be configured about recovering a schema.
+type RecoveryOptions struct {
+	Options                   // How the requests are sent, only the Client, Transport, Header, and Timeout are used
+	Wordlist    []string      // The names to try as fields, arguments, input fields, and enum values, DefaultWordlist when it is nil
+	MaxRequests int           // The most requests that are sent, no limit when it is zero
+	Interval    time.Duration // The least time between the start of two requests
+}
+
+// probeName is the name of a field that does not exist. It is in every recovery request, so that the request always
+// fails validation, and is never executed, which matters for mutations.
+const probeName = "graphqlPostmanProbe"
+
+// probeValue is a value that no scalar, enum, or input object accepts, the error about it names the type of the position.
+const probeValue = "{" + probeName + ": 0}"
+
+// recoveryBatchSize is the number of attempts in a request, few enough to stay below the error limit of most servers.
+const recoveryBatchSize = 20
+
+// builtinScalars contains the scalars that every schema has.
+var builtinScalars = map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
+
+// The validation errors of graphql-js, which most servers use, that tell something about the schema.
+var (
+	cannotQueryField      = regexp.MustCompile(`^Cannot query field "(\w+)" on type "(\w+)"`)
+	mustHaveSelection     = regexp.MustCompile(`^Field "(\w+)" of type "([\w!\[\]]+)" must have a selection of subfields`)
+	mustNotHaveSelection  = regexp.MustCompile(`^Field "(\w+)" must not have a selection since type "([\w!\[\]]+)" has no subfields`)
+	requiredArgument      = regexp.MustCompile(`^Field "(\w*)" argument "(\w+)" of type "([\w!\[\]]*)" is required`)
+	unknownArgument       = regexp.MustCompile(`^Unknown argument "(\w+)" on field`)
+	notDefinedByType      = regexp.MustCompile(`^Field "(\w+)" is not defined by type "(\w+)"`)
+	requiredInputField    = regexp.MustCompile(`^Field "(\w*)\.(\w+)" of required type "([\w!\[\]]*)" was not provided`)
+	expectedValue         = regexp.MustCompile(`^Expected value of type "([\w!\[\]]+)", found (null\.)?(?: At "([\w.]+)"\.)?`)
+	enumCannotRepresent   = regexp.MustCompile(`^Enum "(\w+)" cannot represent`)
+	valueNotInEnum        = regexp.MustCompile(`^Value "(\w+)" does not exist in "(\w+)" enum`)
+	scalarCannotRepresent = regexp.MustCompile(`^(\w+) cannot represent`)
+	didYouMean            = regexp.MustCompile(`Did you mean (?:the enum value )?(.+)\?`)
+	quotedName            = regexp.MustCompile(`"(\w+)"`)
+	validName             = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+	errorLimit            = regexp.MustCompile(`error limit reached|maximum number of validation errors`)
+)
+
+// The validation errors of graphql-java, which tell less: they have no suggestions, and the errors about values do not
+// always name the type. Those are rewritten to the graphql-js errors that tell the same, see javaMessages.
+var (
+	javaFieldUndefined     = regexp.MustCompile(`Field '(\w+)' in type '(\w+)' is undefined`)
+	javaSelectionRequired  = regexp.MustCompile(`Sub ?selection required for type '?([\w!\[\]]+)'? of field '?(\w+)'?`)
+	javaSelectionForbidden = regexp.MustCompile(`Sub ?selection not allowed on leaf type '?([\w!\[\]]+)'? of field '?(\w+)'?`)
+	javaMissingArgument    = regexp.MustCompile(`Missing field argument '?(\w+)'?`)
+	javaUnknownArgument    = regexp.MustCompile(`Unknown field argument '?(\w+)'?`)
+	javaNotNull            = regexp.MustCompile(`argument '([\w.]+)' with value '.*' must not be null`)
+	javaExtraField         = regexp.MustCompile(`argument '[\w.]+' with value '.*' contains a field not in '(\w+)': '(\w+)'`)
+	javaMissingFields      = regexp.MustCompile(`argument '[\w.]+' with value '.*' is missing required fields '\[([\w, ]*)\]'`)
+	javaNotValid           = regexp.MustCompile(`argument '[\w.]+' with value '.*' is not a valid '(\w+)'(.*)`)
+)
+
+// javaMessages rewrites a validation error of graphql-java to the ones of graphql-js that tell the same, with an empty
+// name or type where graphql-java leaves it out: a required input field of an unknown type is ".name", and a value
+// that is only known to be non-null has the type "!". As a value is only valid when every required input field in
+// it is given, the errors about null name the argument or input field, like ` At "input.name".`, after the message.
+// Other errors are returned as they are.
+func javaMessages(message string) []string {
+	if m := javaFieldUndefined.FindStringSubmatch(message); m != nil {
+		return []string{`Cannot query field "` + m[1] + `" on type "` + m[2] + `".`}
+	}
+	if m := javaSelectionRequired.FindStringSubmatch(message); m != nil {
+		return []string{`Field "` + m[2] + `" of type "` + m[1] + `" must have a selection of subfields.`}
+	}
+	if m := javaSelectionForbidden.FindStringSubmatch(message); m != nil {
+		return []string{`Field "` + m[2] + `" must not have a selection since type "` + m[1] + `" has no subfields.`}
+	}
+	if m := javaMissingArgument.FindStringSubmatch(message); m != nil {
+		return []string{`Field "" argument "` + m[1] + `" of type "" is required, but it was not provided.`}
+	}
+	if m := javaUnknownArgument.FindStringSubmatch(message); m != nil {
+		return []string{`Unknown argument "` + m[1] + `" on field.`}
+	}
+	if m := javaNotNull.FindStringSubmatch(message); m != nil {
+		return []string{`Expected value of type "!", found null. At "` + m[1] + `".`}
+	}
+	if m := javaExtraField.FindStringSubmatch(message); m != nil {
+		return []string{`Field "` + m[2] + `" is not defined by type "` + m[1] + `".`}
+	}
+	if m := javaMissingFields.FindStringSubmatch(message); m != nil {
+		var messages []string
+		for _, f := range strings.Split(m[1], ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				messages = append(messages, `Field ".`+f+`" of required type "" was not provided.`)
+			}
+		}
+		return messages
+	}
+	if m := javaNotValid.FindStringSubmatch(message); m != nil {
+		if strings.Contains(strings.ToLower(m[2]), "enum") {
+			return []string{`Enum "` + m[1] + `" cannot represent non-enum value.`}
+		}
+		return []string{m[1] + ` cannot represent the value.`}
+	}
+
+	return []string{message}
+}
+
+// suggestions returns the names in the "Did you mean ...?" of an error message.
+func suggestions(message string) []string {
+	m := didYouMean.FindStringSubmatch(message)
+	if m == nil || strings.HasPrefix(m[1], "to use an inline fragment") {
+		return nil
+	}
+
+	var names []string
+	for _, q := range quotedName.FindAllStringSubmatch(m[1], -1) {
+		names = append(names, q[1])
+	}
+
+	return names
+}
+
+// baseName returns the name of the named type in a type in GraphQL notation, like "User" for "[User!]!".
+func baseName(typ string) string {
+	return strings.Trim(typ, "[]!")
+}
+
+// attempt is a line of a recovery request, and what to do with the errors on that line.
+type attempt struct {
+	operation string             // "query" or "mutation"
+	selection string             // The selection on the root type, like `user(id: null)`
+	handle    func(errs []Error) // Called with the errors on the line of the selection
+}
+
+// position is a place that a value can be given in, an argument of a root field, or an input field somewhere inside it.
+type position struct {
+	operation string
+	field     string
+	path      []string   // The argument, followed by the input fields
+	nulls     [][]string // For every input field in the path, the other input fields that are given null next to it
+}
+
+// with returns the selection that gives a value in the position.
+func (p position) with(value string) string {
+	for i := len(p.path) - 1; i > 0; i-- {
+		value = "{" + p.path[i] + ": " + value + withNulls(p.nulls[i-1]) + "}"
+	}
+
+	return p.field + "(" + p.path[0] + ": " + value + ")"
+}
+
+// child returns the position of an input field of the input object in the position, with null for the other input fields.
+func (p position) child(name string, nulls []string) position {
+	path := append(append([]string{}, p.path...), name)
+	return position{operation: p.operation, field: p.field, path: path, nulls: append(append([][]string{}, p.nulls...), nulls)}
+}
+
+// withNulls returns the input fields that are given null, to put after another input field of an input object.
+func withNulls(fields []string) string {
+	var s string
+	for _, f := range fields {
+		s += ", " + f + ": null"
+	}
+
+	return s
+}
+
+// dotted returns the argument and input fields of the position, separated by dots, like graphql-java names them.
+func (p position) dotted() string {
+	return strings.Join(p.path, ".")
+}
+
+func (p position) String() string {
+	return p.operation + "." + p.field + "(" + p.dotted() + ")"
+}
+
+// recoveredField is a field of a root type, as far as it is recovered.
+type recoveredField struct {
+	name      string
+	typ       string            // The type in GraphQL notation, like "[User!]!", empty when it is not known
+	arguments map[string]string // The types of the arguments, in GraphQL notation
+}
+
+// recoveredType is a type that a root field, an argument, or an input field refers to.
+type recoveredType struct {
+	kind        string
+	inputFields map[string]string // The types of the input fields of an input object, in GraphQL notation
+	required    []string          // The names of the required input fields of an input object
+	enumValues  []string
+}
+
+// recoverer recovers a schema, by sending attempts until there is nothing left to try.
+type recoverer struct {
+	endpoint string
+	options  RecoveryOptions
+	words    []string
+	queue    []attempt
+	seen     map[string]bool // The keys of the probes that are queued already
+	requests int
+	last     time.Time // When the last request was sent
+	java     bool      // Whether the endpoint responds with the validation errors of graphql-java
+	single   bool      // Whether every attempt is sent in a request of its own, as the errors have no locations
+	roots    map[string]string
+	fields   map[string][]*recoveredField // The fields of the root types, by operation
+	types    map[string]*recoveredType
+}
+
+// Recover recovers a part of the schema of an endpoint that has introspection disabled, from the validation errors that
+// it responds with, like the "Did you mean ...?" suggestions. Every name in the wordlist, and every suggestion, is tried
+// as a field of the root types, as an argument of those, and as an input field and enum value of the types of the arguments.
+// The types that the root fields return are only recovered by name.
+//
+// Only use it on endpoints that you are authorized to test. Every request fails validation on purpose, so none is executed.
+func Recover(ctx context.Context, endpoint string, options RecoveryOptions) (*Model, error) {
+	r := recoverer{
+		endpoint: endpoint,
+		options:  options,
+		seen:     make(map[string]bool),
+		roots:    make(map[string]string),
+		fields:   make(map[string][]*recoveredField),
+		types:    make(map[string]*recoveredType),
+	}
+
+	wordlist := options.Wordlist
+	if wordlist == nil {
+		wordlist = DefaultWordlist
+	}
+	unique := make(map[string]bool)
+	for _, w := range wordlist {
+		if validName.MatchString(w) && !unique[w] {
+			unique[w] = true
+			r.words = append(r.words, w)
+		}
+	}
+
+	// The error about the field that does not exist names the root type
+	for _, operation := range []string{"query", "mutation"} {
+		errs, err := r.request(ctx, operation, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range errs {
+			if m := cannotQueryField.FindStringSubmatch(e.Message); m != nil && m[1] == probeName {
+				r.roots[operation] = m[2]
+			}
+		}
+	}
+	if r.roots["query"] == "" {
+		return nil, errors.New("the endpoint does not respond with the validation errors that a schema can be recovered from")
+	}
+
+	for _, operation := range []string{"query", "mutation"} {
+		if r.roots[operation] == "" {
+			continue
+		}
+		for _, w := range r.words {
+			r.discoverField(operation, w)
+		}
+	}
+
+	if err := r.run(ctx); err != nil {
+		if len(r.fields) == 0 {
+			return nil, err
+		}
+		log.WithError(err).Warning("the recovery stopped, continuing with the part of the schema that is recovered")
+	}
+
+	log.Info("Recovered " + strconv.Itoa(len(r.fields["query"])) + " queries and " + strconv.Itoa(len(r.fields["mutation"])) +
+		" mutations with " + strconv.Itoa(r.requests) + " requests")
+
+	return r.model(), nil
+}
+
+// enqueue queues an attempt, unless an attempt with the same key is queued already.
+func (r *recoverer) enqueue(key string, p attempt) {
+	if r.seen[key] {
+		return
+	}
+	r.seen[key] = true
+	r.queue = append(r.queue, p)
+}
+
+// addType adds a type, or changes the kind of a scalar that turns out to be an enum or an input object.
+// The kind of a type that a field returns is not always known, it is assumed to be a scalar then.
+func (r *recoverer) addType(name, kind string) *recoveredType {
+	t, ok := r.types[name]
+	if !ok {
+		t = &recoveredType{kind: kind, inputFields: make(map[string]string)}
+		r.types[name] = t
+	} else if t.kind == "SCALAR" && kind != "SCALAR" && !builtinScalars[name] {
+		t.kind = kind
+	}
+
+	return t
+}
+
+// addRequired adds the name of a required input field.
+func (t *recoveredType) addRequired(name string) {
+	for _, f := range t.required {
+		if f == name {
+			return
+		}
+	}
+	t.required = append(t.required, name)
+}
+
+// nulls returns the required input fields of an input object, except for one, to give null in every value of it.
+// graphql-java reports that required fields are missing before anything else about a value, and only reports the
+// first error, graphql-js reports every error, so there is no need to, and the errors about null would be in the way.
+func (r *recoverer) nulls(typeName, except string) []string {
+	if !r.java {
+		return nil
+	}
+
+	var nulls []string
+	for _, f := range r.types[typeName].required {
+		if f != except {
+			nulls = append(nulls, f)
+		}
+	}
+
+	return nulls
+}
+
+// discoverField tries a name as a field of the root type of an operation.
+func (r *recoverer) discoverField(operation, name string) {
+	r.enqueue("field "+operation+" "+name, attempt{operation: operation, selection: name, handle: func(errs []Error) {
+		for _, e := range errs {
+			if m := cannotQueryField.FindStringSubmatch(e.Message); m != nil && m[1] == name {
+				for _, s := range suggestions(e.Message) {
+					r.discoverField(operation, s)
+				}
+				return
+			}
+		}
+
+		field := &recoveredField{name: name, arguments: make(map[string]string)}
+		r.fields[operation] = append(r.fields[operation], field)
+		for _, e := range errs {
+			if m := mustHaveSelection.FindStringSubmatch(e.Message); m != nil && m[1] == name {
+				field.typ = m[2]
+				r.addType(baseName(m[2]), "OBJECT")
+			}
+			if m := requiredArgument.FindStringSubmatch(e.Message); m != nil && (m[1] == name || m[1] == "") {
+				r.discoverArgument(operation, field, m[2])
+			}
+		}
+
+		// Without a selection, a field that returns a scalar or an enum is valid, with one its type is in the error
+		if field.typ == "" {
+			r.enqueue("leaf "+operation+" "+name, attempt{operation: operation, selection: name + " { __typename }", handle: func(errs []Error) {
+				for _, e := range errs {
+					if m := mustNotHaveSelection.FindStringSubmatch(e.Message); m != nil && m[1] == name {
+						field.typ = m[2]
+						r.addType(baseName(m[2]), "SCALAR")
+					}
+				}
+			}})
+		}
+
+		for _, w := range r.words {
+			r.discoverArgument(operation, field, w)
+		}
+	}})
+}
+
+// discoverArgument tries a name as an argument of a root field.
+func (r *recoverer) discoverArgument(operation string, field *recoveredField, name string) {
+	p := position{operation: operation, field: field.name, path: []string{name}}
+	r.enqueue("argument "+p.String(), attempt{operation: operation, selection: p.with("null"), handle: func(errs []Error) {
+		typ := ""
+		for _, e := range errs {
+			if m := unknownArgument.FindStringSubmatch(e.Message); m != nil && m[1] == name {
+				for _, s := range suggestions(e.Message) {
+					r.discoverArgument(operation, field, s)
+				}
+				return
+			}
+			// Only a non-null argument does not accept null
+			if m := expectedValue.FindStringSubmatch(e.Message); m != nil && m[2] != "" && (m[3] == "" || m[3] == p.dotted()) {
+				typ = m[1]
+			}
+		}
+
+		r.discoverValue(p, typ, nil, func(typ string) {
+			field.arguments[name] = typ
+		})
+	}})
+}
+
+// discoverInputField tries a name as an input field of the input object in a position.
+func (r *recoverer) discoverInputField(p position, typeName, name string) {
+	nulls := r.nulls(typeName, name)
+	r.enqueue("input field "+typeName+" "+name, attempt{operation: p.operation, selection: p.with("{" + name + ": null" + withNulls(nulls) + "}"), handle: func(errs []Error) {
+		typ := ""
+		for _, e := range errs {
+			if m := notDefinedByType.FindStringSubmatch(e.Message); m != nil && m[1] == name && m[2] == typeName {
+				for _, s := range suggestions(e.Message) {
+					r.discoverInputField(p, typeName, s)
+				}
+				return
+			}
+			if m := expectedValue.FindStringSubmatch(e.Message); m != nil && m[2] != "" && (m[3] == "" || m[3] == p.child(name, nil).dotted()) {
+				typ = m[1]
+			}
+			if m := requiredInputField.FindStringSubmatch(e.Message); m != nil && (m[1] == typeName || m[1] == "") {
+				r.discoverInputField(p, typeName, m[2])
+			}
+		}
+
+		r.discoverValue(p.child(name, nulls), typ, nil, func(typ string) {
+			r.types[typeName].inputFields[name] = typ
+		})
+	}})
+}
+
+// discoverEnumValue tries a name as a value of the enum in a position.
+func (r *recoverer) discoverEnumValue(p position, typeName, name string) {
+	if name == "true" || name == "false" || name == "null" {
+		return
+	}
+
+	r.enqueue("enum value "+typeName+" "+name, attempt{operation: p.operation, selection: p.with(name), handle: func(errs []Error) {
+		for _, e := range errs {
+			m := valueNotInEnum.FindStringSubmatch(e.Message)
+			if m != nil && m[1] == name || enumCannotRepresent.MatchString(e.Message) || expectedValue.MatchString(e.Message) {
+				for _, s := range suggestions(e.Message) {
+					r.discoverEnumValue(p, typeName, s)
+				}
+				return
+			}
+		}
+
+		t := r.types[typeName]
+		t.enumValues = append(t.enumValues, name)
+	}})
+}
+
+// discoverValue finds out the type of a position, by giving it a value that no type accepts, with null for the input
+// fields in nulls. The type is passed to set, with the list and non-null wrappers of typ when those are known.
+// The input fields and the enum values of the type are tried next.
+func (r *recoverer) discoverValue(p position, typ string, nulls []string, set func(typ string)) {
+	value := strings.TrimSuffix(probeValue, "}") + withNulls(nulls) + "}"
+
+	r.enqueue("value "+p.String()+" "+value, attempt{operation: p.operation, selection: p.with(value), handle: func(errs []Error) {
+		name, kind := "", ""
+		var required []string
+		for _, e := range errs {
+			if m := enumCannotRepresent.FindStringSubmatch(e.Message); m != nil {
+				name, kind = m[1], "ENUM"
+			} else if m := notDefinedByType.FindStringSubmatch(e.Message); m != nil && m[1] == probeName {
+				name, kind = m[2], "INPUT_OBJECT"
+			} else if m := requiredInputField.FindStringSubmatch(e.Message); m != nil {
+				// The input object that the position is in may have required fields as well
+				required = append(required, m[1]+"."+m[2])
+			} else if m := expectedValue.FindStringSubmatch(e.Message); m != nil && kind == "" && baseName(m[1]) != "" {
+				name, kind = baseName(m[1]), "SCALAR"
+			} else if m := scalarCannotRepresent.FindStringSubmatch(e.Message); m != nil && kind == "" {
+				name, kind = m[1], "SCALAR"
+			}
+		}
+
+		// graphql-java only names an input object when none of its required fields are missing
+		if name == "" && nulls == nil {
+			for _, f := range required {
+				if strings.HasPrefix(f, ".") {
+					nulls = append(nulls, strings.TrimPrefix(f, "."))
+				}
+			}
+			if nulls != nil {
+				r.discoverValue(p, typ, nulls, set)
+				return
+			}
+		}
+
+		if name == "" {
+			if baseName(typ) == "" {
+				log.Info(`The type of "` + p.String() + `" could not be recovered, leaving it out...`)
+				return
+			}
+			name, kind = baseName(typ), "SCALAR"
+		}
+		if baseName(typ) != name {
+			// Only the non-null wrapper is known when the error does not name the type
+			if strings.HasSuffix(typ, "!") {
+				typ = name + "!"
+			} else {
+				typ = name
+			}
+		}
+		set(typ)
+
+		t := r.addType(name, kind)
+		switch kind {
+		case "INPUT_OBJECT":
+			for _, f := range required {
+				if strings.HasPrefix(f, name+".") || strings.HasPrefix(f, ".") {
+					t.addRequired(f[strings.Index(f, ".")+1:])
+				}
+			}
+			for _, f := range nulls {
+				t.addRequired(f)
+			}
+			for _, f := range t.required {
+				r.discoverInputField(p, name, f)
+			}
+			for _, w := range r.words {
+				r.discoverInputField(p, name, w)
+			}
+		case "ENUM":
+			for _, w := range r.words {
+				r.discoverEnumValue(p, name, w)
+				r.discoverEnumValue(p, name, strings.ToUpper(w))
+			}
+		}
+	}})
+}
+
+// run sends the queued attempts in batches, until none are left, or until the maximum number of requests is reached.
+func (r *recoverer) run(ctx context.Context) error {
+	for len(r.queue) > 0 {
+		if r.options.MaxRequests > 0 && r.requests >= r.options.MaxRequests {
+			log.Warning("the maximum number of requests is reached, the recovered schema is incomplete")
+			return nil
+		}
+
+		// Probes can only be in the same request when they are of the same operation
+		operation := r.queue[0].operation
+		size := recoveryBatchSize
+		if r.single {
+			size = 1
+		}
+		var batch, rest []attempt
+		for _, p := range r.queue {
+			if p.operation == operation && len(batch) < size {
+				batch = append(batch, p)
+			} else {
+				rest = append(rest, p)
+			}
+		}
+		r.queue = rest
+
+		selections := make([]string, len(batch))
+		for i, p := range batch {
+			selections[i] = p.selection
+		}
+		errs, err := r.request(ctx, operation, selections)
+		if err != nil {
+			return err
+		}
+
+		// The errors are matched to the attempts by the line they are about. Without a line, the attempt that an error
+		// is about is not known, and the attempts are sent again, one per request, which all of the errors are about then.
+		byProbe := make(map[int][]Error)
+		last, limited, located := -1, false, true
+		for _, e := range errs {
+			switch {
+			case errorLimit.MatchString(e.Message):
+				limited = true
+			case len(e.Locations) > 0:
+				i := e.Locations[0].Line - 3
+				byProbe[i] = append(byProbe[i], e)
+				if i > last {
+					last = i
+				}
+			case r.single:
+				if m := cannotQueryField.FindStringSubmatch(e.Message); m == nil || m[1] != probeName {
+					byProbe[0] = append(byProbe[0], e)
+					last = 0
+				}
+			default:
+				located = false
+			}
+		}
+		if !located {
+			log.Warning("the validation errors of the endpoint have no locations, sending one attempt per request")
+			r.single = true
+			r.queue = append(batch, r.queue...)
+			continue
+		}
+		if limited && last < 0 {
+			return errors.New("the endpoint stops validating before it gets to the attempts, so they can not be told apart from hits")
+		}
+
+		for i, p := range batch {
+			// When the server stops validating after too many errors, the attempts from the last one with an error are
+			// sent again, that one may not have all of its errors. The first one is always handled, to make progress.
+			if limited && i >= last && i > 0 {
+				r.queue = append(r.queue, p)
+				continue
+			}
+			p.handle(byProbe[i])
+		}
+	}
+
+	return nil
+}
+
+// request sends a request with the selections on the root type of an operation, and returns the errors of the response.
+// The selection on the second line is the field that does not exist, the selections start on the third line.
+func (r *recoverer) request(ctx context.Context, operation string, selections []string) ([]Error, error) {
+	if wait := r.options.Interval - time.Since(r.last); wait > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	r.last = time.Now()
+	r.requests++
+
+	var w queryWriter
+	w.line(operation + " SchemaRecovery {")
+	w.line(probeName)
+	for i, s := range selections {
+		w.line("p" + strconv.Itoa(i) + ": " + s)
+	}
+	w.line("}")
+
+	transport := r.options.Transport
+	if transport == "" {
+		transport = TransportPost
+	}
+	body, _, err := send(ctx, r.endpoint, transport, document{Query: w.String(), OperationName: "SchemaRecovery"}, r.options.Options)
+
+	// Most servers respond to a request that fails validation with a 400 status
+	var responseErr *ResponseError
+	if errors.As(err, &responseErr) {
+		return r.rewrite(responseErr.Errors), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Errors []Error `json:"errors"`
+	}
+	if err = json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Errors) == 0 {
+		return nil, errors.New("the endpoint responded without errors to a request that can not be valid")
+	}
+
+	return r.rewrite(response.Errors), nil
+}
+
+// rewrite rewrites the errors of graphql-java to the ones of graphql-js, see javaMessages.
+func (r *recoverer) rewrite(errs []Error) []Error {
+	var rewritten []Error
+	for _, e := range errs {
+		messages := javaMessages(e.Message)
+		if len(messages) != 1 || messages[0] != e.Message {
+			r.java = true
+		}
+		for _, message := range messages {
+			e.Message = message
+			rewritten = append(rewritten, e)
+		}
+	}
+
+	return rewritten
+}
+
+// typeRef converts a type in GraphQL notation to a type reference.
+func (r *recoverer) typeRef(typ string) TypeRef {
+	if strings.HasSuffix(typ, "!") {
+		ofType := r.typeRef(strings.TrimSuffix(typ, "!"))
+		return TypeRef{Kind: "NON_NULL", OfType: &ofType}
+	}
+	if strings.HasPrefix(typ, "[") && strings.HasSuffix(typ, "]") {
+		ofType := r.typeRef(typ[1 : len(typ)-1])
+		return TypeRef{Kind: "LIST", OfType: &ofType}
+	}
+
+	kind := "SCALAR"
+	if t, ok := r.types[typ]; ok {
+		kind = t.kind
+	}
+	return TypeRef{Named: Named{Name: typ}, Kind: kind}
+}
+
+// model returns the recovered schema, sorted by name, so that the result is the same for every run.
+func (r *recoverer) model() *Model {
+	var model Model
+	schema := &model.Data.Schema
+	schema.QueryType.Name = r.roots["query"]
+	schema.MutationType.Name = r.roots["mutation"]
+
+	for _, operation := range []string{"query", "mutation"} {
+		if r.roots[operation] == "" {
+			continue
+		}
+
+		root := Type{Named: Named{Name: r.roots[operation]}}
+		for _, f := range r.fields[operation] {
+			if f.typ == "" {
+				log.Info(`The type of "` + operation + "." + f.name + `" could not be recovered, leaving it out...`)
+				continue
+			}
+
+			field := TypeField{Named: Named{Name: f.name}, Type: r.typeRef(f.typ)}
+			for _, name := range sortedNames(f.arguments) {
+				field.Arguments = append(field.Arguments, NamedTypeRef{Named: Named{Name: name}, Type: r.typeRef(f.arguments[name])})
+			}
+			root.Fields = append(root.Fields, field)
+		}
+		sort.Slice(root.Fields, func(i, j int) bool {
+			return root.Fields[i].Name < root.Fields[j].Name
+		})
+		schema.Types = append(schema.Types, root)
+	}
+
+	names := make([]string, 0, len(r.types))
+	for name := range r.types {
+		// A root type that a field returns is in the schema already
+		if name != r.roots["query"] && name != r.roots["mutation"] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		t := r.types[name]
+		recovered := Type{Named: Named{Name: name}}
+		for _, f := range sortedNames(t.inputFields) {
+			recovered.InputFields = append(recovered.InputFields, NamedTypeRef{Named: Named{Name: f}, Type: r.typeRef(t.inputFields[f])})
+		}
+		sort.Strings(t.enumValues)
+		for _, v := range t.enumValues {
+			recovered.EnumValues = append(recovered.EnumValues, Named{Name: v})
+		}
+		schema.Types = append(schema.Types, recovered)
+	}
+
+	return &model
+}
+
+// sortedNames returns the keys of a map of types in GraphQL notation, sorted.
+func sortedNames(m map[string]string) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,628 @@
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestJavaMessages(t *testing.T) {
+	for _, tt := range []struct {
+		message string
+		want    []string
+	}{
+		{
+			"Validation error (FieldUndefined@[usr]) : Field 'usr' in type 'Query' is undefined",
+			[]string{`Cannot query field "usr" on type "Query".`},
+		},
+		{
+			"Validation error of type FieldUndefined: Field 'usr' in type 'Query' is undefined @ 'usr'",
+			[]string{`Cannot query field "usr" on type "Query".`},
+		},
+		{
+			"Validation error (SubselectionRequired@[p0]) : Subselection required for type 'User' of field 'user'",
+			[]string{`Field "user" of type "User" must have a selection of subfields.`},
+		},
+		{
+			"Validation error of type SubSelectionRequired: Sub selection required for type [User!]! of field users @ 'users'",
+			[]string{`Field "users" of type "[User!]!" must have a selection of subfields.`},
+		},
+		{
+			"Validation error (SubselectionNotAllowed@[p0]) : Subselection not allowed on leaf type 'String' of field 'version'",
+			[]string{`Field "version" must not have a selection since type "String" has no subfields.`},
+		},
+		{
+			"Validation error (MissingFieldArgument@[p0]) : Missing field argument 'id'",
+			[]string{`Field "" argument "id" of type "" is required, but it was not provided.`},
+		},
+		{
+			"Validation error of type MissingFieldArgument: Missing field argument id @ 'user'",
+			[]string{`Field "" argument "id" of type "" is required, but it was not provided.`},
+		},
+		{
+			"Validation error (UnknownArgument@[p0]) : Unknown field argument 'idd'",
+			[]string{`Unknown argument "idd" on field.`},
+		},
+		{
+			"Validation error (WrongType@[p0]) : argument 'id' with value 'NullValue{}' must not be null",
+			[]string{`Expected value of type "!", found null. At "id".`},
+		},
+		{
+			"Validation error (WrongType@[p0]) : argument 'input.name' with value 'NullValue{}' must not be null",
+			[]string{`Expected value of type "!", found null. At "input.name".`},
+		},
+		{
+			"Validation error (WrongType@[p0]) : argument 'filter' with value 'ObjectValue{objectFields=[ObjectField{name='rol', value=NullValue{}}]}' contains a field not in 'UserFilter': 'rol'",
+			[]string{`Field "rol" is not defined by type "UserFilter".`},
+		},
+		{
+			"Validation error (WrongType@[p0]) : argument 'input' with value 'ObjectValue{objectFields=[]}' is missing required fields '[name, email]'",
+			[]string{`Field ".name" of required type "" was not provided.`, `Field ".email" of required type "" was not provided.`},
+		},
+		{
+			"Validation error (WrongType@[p0]) : argument 'filter.role' with value 'EnumValue{name='ADM'}' is not a valid 'Role' - Literal value not in allowable values for enum 'Role' - 'EnumValue{name='ADM'}'",
+			[]string{`Enum "Role" cannot represent non-enum value.`},
+		},
+		{
+			"Validation error (WrongType@[p0]) : argument 'id' with value 'ObjectValue{objectFields=[]}' is not a valid 'ID' - Expected an AST type of 'IntValue' or 'StringValue' but it was a 'ObjectValue'",
+			[]string{`ID cannot represent the value.`},
+		},
+		{
+			`Cannot query field "usr" on type "Query". Did you mean "user"?`,
+			[]string{`Cannot query field "usr" on type "Query". Did you mean "user"?`},
+		},
+	} {
+		t.Run(tt.message, func(t *testing.T) {
+			if got := javaMessages(tt.message); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestions(t *testing.T) {
+	for _, tt := range []struct {
+		message string
+		want    []string
+	}{
+		{`Cannot query field "usr" on type "Query". Did you mean "user" or "users"?`, []string{"user", "users"}},
+		{`Cannot query field "usr" on type "Query". Did you mean "user", "users", or "userById"?`, []string{"user", "users", "userById"}},
+		{`Value "ADM" does not exist in "Role" enum. Did you mean the enum value "ADMIN"?`, []string{"ADMIN"}},
+		{`Cannot query field "id" on type "Node". Did you mean to use an inline fragment on "User"?`, nil},
+		{`Cannot query field "usr" on type "Query".`, nil},
+	} {
+		t.Run(tt.message, func(t *testing.T) {
+			if got := suggestions(tt.message); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// testField is a field of a root type of the schema that the test server validates against.
+type testField struct {
+	typ       string
+	arguments [][2]string // The names and types of the arguments, in order
+}
+
+// testInput is an input object of the schema that the test server validates against.
+type testInput [][2]string // The names and types of the input fields, in order
+
+var (
+	testRoots  = map[string]string{"query": "Query", "mutation": "Mutation"}
+	testFields = map[string]map[string]testField{
+		"Query": {
+			"user":    {"User", [][2]string{{"id", "ID!"}}},
+			"users":   {"[User!]!", [][2]string{{"filter", "UserFilter"}, {"role", "Role"}, {"first", "Int"}}},
+			"version": {"String", nil},
+		},
+		"Mutation": {
+			"createUser": {"User", [][2]string{{"input", "CreateUserInput!"}}},
+		},
+	}
+	testInputs = map[string]testInput{
+		"UserFilter":      {{"name", "String"}, {"role", "Role"}, {"nested", "UserFilter"}},
+		"CreateUserInput": {{"name", "String!"}, {"email", "String!"}, {"born", "DateTime"}, {"address", "AddressInput"}},
+		"AddressInput":    {{"city", "String!"}, {"street", "String"}},
+	}
+	testEnums   = map[string][]string{"Role": {"ADMIN", "MEMBER"}}
+	testObjects = map[string]bool{"User": true}
+)
+
+// testRecovered is what is recovered of the schema, see describe.
+var testRecovered = []string{
+	"AddressInput.city: String!",
+	"AddressInput.street: String",
+	"CreateUserInput.address: AddressInput",
+	"CreateUserInput.born: DateTime",
+	"CreateUserInput.email: String!",
+	"CreateUserInput.name: String!",
+	"Mutation.createUser(input: CreateUserInput!): User",
+	"Query.user(id: ID!): User",
+	"Query.users(filter: UserFilter, first: Int, role: Role): [User!]!",
+	"Query.version: String",
+	"Role = ADMIN | MEMBER",
+	"UserFilter.name: String",
+	"UserFilter.nested: UserFilter",
+	"UserFilter.role: Role",
+}
+
+// dialect is how a test server reports validation errors.
+type dialect struct {
+	status      int  // The status of a response with validation errors
+	suggestions bool // Whether the errors have "Did you mean ...?" suggestions
+	code        bool // Whether the errors have an extensions.code, like with Apollo Server
+	java        bool // Whether the errors are the ones of graphql-java, which reports the first error of a value only
+	maxErrors   int  // The most errors that are reported before validation is aborted, no limit when it is zero
+	noLocations bool // Whether the errors have no locations
+}
+
+func TestRecover(t *testing.T) {
+	names := []string{"user", "users", "version", "createUser", "id", "filter", "role", "first", "input", "name", "email",
+		"born", "address", "city", "street", "nested", "admin", "member", "unknown"}
+	// The first letters of every name, only the suggestions complete them
+	prefixes := []string{"use", "ver", "cre", "id", "fil", "rol", "fir", "inp", "nam", "ema", "bor", "add", "cit", "str",
+		"nes", "adm", "mem"}
+
+	for _, tt := range []struct {
+		name     string
+		dialect  dialect
+		wordlist []string
+	}{
+		{"graphql-js", dialect{status: http.StatusOK, suggestions: true}, prefixes},
+		{"graphql-js, with an error limit", dialect{status: http.StatusOK, suggestions: true, maxErrors: 4}, prefixes},
+		{"Apollo Server", dialect{status: http.StatusBadRequest, suggestions: true, code: true}, prefixes},
+		{"Apollo Server, without suggestions", dialect{status: http.StatusBadRequest, code: true}, names},
+		{"graphql-java", dialect{status: http.StatusOK, java: true}, names},
+		{"without locations", dialect{status: http.StatusOK, suggestions: true, noLocations: true}, prefixes},
+		{"without locations, with an error limit", dialect{status: http.StatusOK, suggestions: true, noLocations: true, maxErrors: 4}, prefixes},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				var doc document
+				body, _ := ioutil.ReadAll(r.Body)
+				if err := json.Unmarshal(body, &doc); err != nil {
+					t.Error(err)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.dialect.status)
+				json.NewEncoder(w).Encode(map[string]interface{}{"errors": tt.dialect.validate(t, doc.Query)})
+			}))
+			defer server.Close()
+
+			model, err := Recover(context.Background(), server.URL, RecoveryOptions{Wordlist: tt.wordlist})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := describe(model); !reflect.DeepEqual(got, testRecovered) {
+				t.Errorf("recovered\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(testRecovered, "\n"))
+			}
+			t.Log(requests, "requests")
+		})
+	}
+}
+
+func TestRecoverErrorLimitBeforeTheAttempts(t *testing.T) {
+	// Only the error about the field that does not exist is reported, so none of the attempts is validated
+	d := dialect{status: http.StatusOK, suggestions: true, maxErrors: 1}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var doc document
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &doc); err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": d.validate(t, doc.Query)})
+	}))
+	defer server.Close()
+
+	if model, err := Recover(context.Background(), server.URL, RecoveryOptions{Wordlist: []string{"user", "version"}}); err == nil {
+		t.Errorf("got no error, but the recovered\n%s", strings.Join(describe(model), "\n"))
+	}
+}
+
+// describe returns a line for every field, input field, and enum of a model, sorted.
+func describe(model *Model) []string {
+	var lines []string
+	for _, typ := range model.Data.Schema.Types {
+		for _, f := range typ.Fields {
+			var arguments []string
+			for _, a := range f.Arguments {
+				arguments = append(arguments, a.Name+": "+notation(a.Type))
+			}
+			line := typ.Name + "." + f.Name
+			if arguments != nil {
+				line += "(" + strings.Join(arguments, ", ") + ")"
+			}
+			lines = append(lines, line+": "+notation(f.Type))
+		}
+		for _, f := range typ.InputFields {
+			lines = append(lines, typ.Name+"."+f.Name+": "+notation(f.Type))
+		}
+		if typ.EnumValues != nil {
+			var values []string
+			for _, v := range typ.EnumValues {
+				values = append(values, v.Name)
+			}
+			lines = append(lines, typ.Name+" = "+strings.Join(values, " | "))
+		}
+	}
+	sort.Strings(lines)
+
+	return lines
+}
+
+// notation returns a type reference in GraphQL notation.
+func notation(ref TypeRef) string {
+	switch ref.Kind {
+	case "NON_NULL":
+		return notation(*ref.OfType) + "!"
+	case "LIST":
+		return "[" + notation(*ref.OfType) + "]"
+	}
+
+	return ref.Name
+}
+
+// testLine is a line of a recovery request, with a field of a root type, and at most one argument.
+var testLine = regexp.MustCompile(`^p\d+: (\w+)(?:\((\w+): (.*)\))?( \{ __typename \})?$`)
+
+// validate returns the validation errors of a recovery request.
+func (d dialect) validate(t *testing.T, query string) []Error {
+	var errs []Error
+	aborted := false
+	lines := strings.Split(query, "\n")
+	root := testRoots[strings.Fields(lines[0])[0]]
+
+	for i, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		add := func(message string) {
+			if aborted {
+				return
+			}
+			if d.maxErrors > 0 && len(errs) == d.maxErrors {
+				errs = append(errs, Error{Message: "Too many validation errors, error limit reached. Validation aborted."})
+				aborted = true
+				return
+			}
+			e := Error{Message: message}
+			if !d.noLocations {
+				e.Locations = []Location{{Line: i + 2, Column: 1}}
+			}
+			if d.code {
+				e.Extensions = map[string]interface{}{"code": "GRAPHQL_VALIDATION_FAILED"}
+			}
+			errs = append(errs, e)
+		}
+
+		if line == "" || line == "}" {
+			continue
+		}
+		if line == probeName {
+			d.undefinedField(add, probeName, root)
+			continue
+		}
+		m := testLine.FindStringSubmatch(line)
+		if m == nil {
+			t.Errorf("the test server can not validate %q", line)
+			continue
+		}
+
+		field, ok := testFields[root][m[1]]
+		if !ok {
+			d.undefinedField(add, m[1], root)
+			continue
+		}
+
+		leaf := !testObjects[baseName(field.typ)]
+		switch {
+		case leaf && m[4] != "" && d.java:
+			add("Validation error (SubselectionNotAllowed@[" + m[1] + "]) : Subselection not allowed on leaf type '" + field.typ + "' of field '" + m[1] + "'")
+		case leaf && m[4] != "":
+			add(`Field "` + m[1] + `" must not have a selection since type "` + field.typ + `" has no subfields.`)
+		case !leaf && m[4] == "" && d.java:
+			add("Validation error (SubselectionRequired@[" + m[1] + "]) : Subselection required for type '" + field.typ + "' of field '" + m[1] + "'")
+		case !leaf && m[4] == "":
+			add(`Field "` + m[1] + `" of type "` + field.typ + `" must have a selection of subfields. Did you mean "` + m[1] + ` { ... }"?`)
+		}
+
+		if m[2] != "" {
+			typ := ""
+			var names []string
+			for _, a := range field.arguments {
+				names = append(names, a[0])
+				if a[0] == m[2] {
+					typ = a[1]
+				}
+			}
+			value, rest := parseValue(t, m[3])
+			if rest != "" {
+				t.Errorf("the test server can not parse %q", m[3])
+			}
+
+			switch {
+			case typ != "":
+				d.validateValue(add, m[2], typ, value)
+			case d.java:
+				add("Validation error (UnknownArgument@[" + m[1] + "]) : Unknown field argument '" + m[2] + "'")
+			default:
+				add(`Unknown argument "` + m[2] + `" on field "` + root + "." + m[1] + `".` + d.didYouMean("", m[2], names))
+			}
+		}
+
+		for _, a := range field.arguments {
+			if strings.HasSuffix(a[1], "!") && a[0] != m[2] {
+				if d.java {
+					add("Validation error (MissingFieldArgument@[" + m[1] + "]) : Missing field argument '" + a[0] + "'")
+				} else {
+					add(`Field "` + m[1] + `" argument "` + a[0] + `" of type "` + a[1] + `" is required, but it was not provided.`)
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+func (d dialect) undefinedField(add func(string), name, root string) {
+	if d.java {
+		add("Validation error (FieldUndefined@[" + name + "]) : Field '" + name + "' in type '" + root + "' is undefined")
+		return
+	}
+
+	var names []string
+	for n := range testFields[root] {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	add(`Cannot query field "` + name + `" on type "` + root + `".` + d.didYouMean("", name, names))
+}
+
+// didYouMean returns the suggestions for a name, the names that start with its first three letters.
+func (d dialect) didYouMean(prefix, name string, names []string) string {
+	if !d.suggestions {
+		return ""
+	}
+
+	start := strings.ToLower(name)
+	if len(start) > 3 {
+		start = start[:3]
+	}
+	var similar []string
+	for _, n := range names {
+		if n != name && strings.HasPrefix(strings.ToLower(n), start) {
+			similar = append(similar, `"`+n+`"`)
+		}
+	}
+
+	switch len(similar) {
+	case 0:
+		return ""
+	case 1:
+		return " Did you mean " + prefix + similar[0] + "?"
+	}
+	return " Did you mean " + prefix + strings.Join(similar[:len(similar)-1], ", ") + ", or " + similar[len(similar)-1] + "?"
+}
+
+// validateValue adds the errors about a value of a type, graphql-java stops at the first one, and returns false then.
+func (d dialect) validateValue(add func(string), argument, typ string, value *testValue) bool {
+	invalid := func(js string) bool {
+		if d.java {
+			detail := "Expected an AST type of 'StringValue' but it was a '" + value.kind + "'"
+			if testEnums[baseName(typ)] != nil {
+				detail = "Literal value not in allowable values for enum '" + baseName(typ) + "' - '" + value.java() + "'"
+			}
+			add("Validation error (WrongType@[" + argument + "]) : argument '" + argument + "' with value '" + value.java() + "' is not a valid '" + baseName(typ) + "' - " + detail)
+		} else {
+			add(js)
+		}
+		return false
+	}
+
+	if value.kind == "NullValue" {
+		if !strings.HasSuffix(typ, "!") {
+			return true
+		}
+		if d.java {
+			add("Validation error (WrongType@[" + argument + "]) : argument '" + argument + "' with value 'NullValue{}' must not be null")
+		} else {
+			add(`Expected value of type "` + typ + `", found null.`)
+		}
+		return false
+	}
+
+	named := strings.TrimSuffix(typ, "!")
+	if strings.HasPrefix(named, "[") {
+		return d.validateValue(add, argument, named[1:len(named)-1], value)
+	}
+
+	switch {
+	case named == "String" || named == "ID":
+		if value.kind != "StringValue" {
+			return invalid(named + " cannot represent a non string value: " + value.String())
+		}
+	case named == "Int":
+		if value.kind != "IntValue" {
+			return invalid("Int cannot represent non-integer value: " + value.String())
+		}
+	case named == "DateTime":
+		return invalid(`Expected value of type "DateTime", found ` + value.String() + "; invalid date")
+	case testEnums[named] != nil:
+		if value.kind != "EnumValue" {
+			return invalid(`Enum "` + named + `" cannot represent non-enum value: ` + value.String() + ".")
+		}
+		for _, v := range testEnums[named] {
+			if v == value.name {
+				return true
+			}
+		}
+		return invalid(`Value "` + value.name + `" does not exist in "` + named + `" enum.` + d.didYouMean("the enum value ", value.name, testEnums[named]))
+	case testInputs[named] != nil:
+		if value.kind != "ObjectValue" {
+			return invalid(`Expected value of type "` + typ + `", found ` + value.String() + ".")
+		}
+		return d.validateObject(add, argument, named, value)
+	}
+
+	return true
+}
+
+// validateObject adds the errors about an input object value, graphql-java reports missing fields before anything else.
+func (d dialect) validateObject(add func(string), argument, named string, value *testValue) bool {
+	var missing, names []string
+	for _, f := range testInputs[named] {
+		names = append(names, f[0])
+		if _, ok := value.field(f[0]); !ok && strings.HasSuffix(f[1], "!") {
+			missing = append(missing, f[0])
+		}
+	}
+
+	if d.java && missing != nil {
+		add("Validation error (WrongType@[" + argument + "]) : argument '" + argument + "' with value '" + value.java() + "' is missing required fields '[" + strings.Join(missing, ", ") + "]'")
+		return false
+	}
+
+	valid := true
+	for _, f := range value.fields {
+		typ := ""
+		for _, field := range testInputs[named] {
+			if field[0] == f.name {
+				typ = field[1]
+			}
+		}
+
+		switch {
+		case typ != "":
+			if !d.validateValue(add, argument+"."+f.name, typ, f.value) && d.java {
+				return false
+			}
+		case d.java:
+			add("Validation error (WrongType@[" + argument + "]) : argument '" + argument + "' with value '" + value.java() + "' contains a field not in '" + named + "': '" + f.name + "'")
+			return false
+		default:
+			add(`Field "` + f.name + `" is not defined by type "` + named + `".` + d.didYouMean("", f.name, names))
+			valid = false
+		}
+	}
+
+	for _, f := range missing {
+		for _, field := range testInputs[named] {
+			if field[0] == f {
+				add(`Field "` + named + "." + f + `" of required type "` + field[1] + `" was not provided.`)
+			}
+		}
+	}
+
+	return valid && missing == nil
+}
+
+// testValue is a value in a recovery request, the kind is the name of the class of graphql-java.
+type testValue struct {
+	kind   string
+	name   string // The value of an enum, string, or int
+	fields []testObjectField
+}
+
+type testObjectField struct {
+	name  string
+	value *testValue
+}
+
+func (v *testValue) field(name string) (*testValue, bool) {
+	for _, f := range v.fields {
+		if f.name == name {
+			return f.value, true
+		}
+	}
+
+	return nil, false
+}
+
+func (v *testValue) String() string {
+	switch v.kind {
+	case "NullValue":
+		return "null"
+	case "ObjectValue":
+		var fields []string
+		for _, f := range v.fields {
+			fields = append(fields, f.name+": "+f.value.String())
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+
+	return v.name
+}
+
+// java returns the value like graphql-java prints it in its errors.
+func (v *testValue) java() string {
+	switch v.kind {
+	case "NullValue":
+		return "NullValue{}"
+	case "ObjectValue":
+		var fields []string
+		for _, f := range v.fields {
+			fields = append(fields, "ObjectField{name='"+f.name+"', value="+f.value.java()+"}")
+		}
+		return "ObjectValue{objectFields=[" + strings.Join(fields, ", ") + "]}"
+	case "EnumValue":
+		return "EnumValue{name='" + v.name + "'}"
+	}
+
+	return v.kind + "{value=" + v.name + "}"
+}
+
+var testToken = regexp.MustCompile(`^\s*("[^"]*"|-?\d+|\w+|[{}:,])`)
+
+// parseValue parses the value at the start of s, and returns the rest of s.
+func parseValue(t *testing.T, s string) (*testValue, string) {
+	token, s := nextToken(t, s)
+	switch {
+	case token == "null":
+		return &testValue{kind: "NullValue"}, s
+	case token == "{":
+		v := &testValue{kind: "ObjectValue"}
+		for {
+			var name string
+			if name, s = nextToken(t, s); name == "}" {
+				return v, s
+			}
+			if token, s = nextToken(t, s); token != ":" {
+				t.Errorf("expected a colon after %q", name)
+			}
+			var value *testValue
+			value, s = parseValue(t, s)
+			v.fields = append(v.fields, testObjectField{name: name, value: value})
+			if token, s = nextToken(t, s); token == "}" {
+				return v, s
+			}
+		}
+	case strings.HasPrefix(token, `"`):
+		return &testValue{kind: "StringValue", name: token}, s
+	}
+
+	if _, err := strconv.Atoi(token); err == nil {
+		return &testValue{kind: "IntValue", name: token}, s
+	}
+	return &testValue{kind: "EnumValue", name: token}, s
+}
+
+func nextToken(t *testing.T, s string) (string, string) {
+	m := testToken.FindStringSubmatch(s)
+	if m == nil {
+		t.Errorf("the test server can not parse %q", s)
+		return "}", ""
+	}
+
+	return m[1], strings.TrimLeft(s[len(m[0]):], " ")
+}
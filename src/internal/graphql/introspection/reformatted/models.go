@@ -1,5 +1,10 @@
 package reformatted
 
+import (
+	"sort"
+	"strings"
+)
+
 // TypeRef is the reformatted version fo introspection.TypeRef.
 // The main benefit of this one, is that the data is flat, there are no "infinite"
 // layers deep that you'll have to go through to determine the exact typing.
@@ -11,6 +16,31 @@ type TypeRef struct {
 	ListNonNull     bool   // Whether or not the list is nullable
 	TwoDList        bool   // Whether or not the type is in a double list
 	TwoDListNonNull bool   // Whether or not the double list is nullable
+	Truncated       string // Why the type is incomplete, empty when it is complete
+}
+
+// String returns the type in the GraphQL notation, e.g. "[String!]!".
+func (t TypeRef) String() string {
+	str := t.Name
+	if t.NonNull {
+		str += "!"
+	}
+
+	if t.List {
+		str = "[" + str + "]"
+		if t.ListNonNull {
+			str += "!"
+		}
+	}
+
+	if t.TwoDList {
+		str = "[" + str + "]"
+		if t.TwoDListNonNull {
+			str += "!"
+		}
+	}
+
+	return str
 }
 
 // Type is generic type that can describe a scalar, input,
@@ -26,11 +56,13 @@ type TypeRef struct {
 // | Union     | Name, PossibleTypes           |
 //
 type Type struct {
-	Name          string
-	Fields        map[string]TypeRef // The key is the name of the field, the value is the value
-	InputFields   map[string]TypeRef // The key is the name of the field, the value is the value
-	EnumValues    []string
-	PossibleTypes []TypeRef
+	Name           string
+	SpecifiedByURL string             // The specification of a scalar, empty when it is unknown
+	OneOf          bool               // Whether exactly one of the input fields has to be set
+	Fields         map[string]TypeRef // The key is the name of the field, the value is the value
+	InputFields    map[string]TypeRef // The key is the name of the field, the value is the value
+	EnumValues     []string
+	PossibleTypes  []TypeRef
 }
 
 // Operation is a struct that contains the data needed for a GraphQL query or mutation.
@@ -40,9 +72,38 @@ type Operation struct {
 	Type      TypeRef            // What the operation returns
 }
 
+// Signature returns the signature of the operation in the GraphQL notation,
+// e.g. "user(id: ID!): User", the arguments are sorted by name.
+func (o Operation) Signature() string {
+	names := make([]string, 0, len(o.Arguments))
+	for name := range o.Arguments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	arguments := make([]string, len(names))
+	for i, name := range names {
+		arguments[i] = name + ": " + o.Arguments[name].String()
+	}
+
+	signature := o.Name
+	if len(arguments) > 0 {
+		signature += "(" + strings.Join(arguments, ", ") + ")"
+	}
+
+	return signature + ": " + o.Type.String()
+}
+
+// Truncation is a type reference that could not be reformatted completely.
+type Truncation struct {
+	Path   string `json:"path"`   // Where the type reference is, e.g. "Query.user(id)" or "UserInput.tags"
+	Reason string `json:"reason"` // Why it is incomplete
+}
+
 // Model is the reformatted version of the introspection.Model.
 type Model struct {
-	Mutations []Operation
-	Queries   []Operation
-	Types     map[string]Type
+	Mutations   []Operation
+	Queries     []Operation
+	Types       map[string]Type
+	Truncations []Truncation
 }
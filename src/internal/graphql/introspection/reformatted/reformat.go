@@ -7,6 +7,19 @@ import (
 	"strings"
 )
 
+// Reasons why a type reference is truncated.
+const (
+	TruncatedDimensions = "lists with more than two dimensions are not supported"
+	TruncatedDepth      = "the type has no name, the introspection query does not go deep enough"
+)
+
+// omitted returns whether an argument or input field is left out, which the deprecated ones are, like deprecated fields.
+// Deprecated ones that are non-null are kept though, as the operation is not valid without them. Some servers do allow
+// deprecating those, and only return them with includeDeprecated: true, which is why the deprecated ones are introspected.
+func omitted(v introspection.NamedTypeRef) bool {
+	return v.IsDeprecated && strings.ToLower(v.Type.Kind) != kind.NonNull
+}
+
 // reformatTypeRef reformats an introspection.TypeRef to a reformatted.TypeRef.
 func reformatTypeRef(typeRef introspection.TypeRef) TypeRef {
 	var t TypeRef
@@ -23,6 +36,7 @@ func reformatTypeRef(typeRef introspection.TypeRef) TypeRef {
 			if t.List {
 				if t.TwoDList {
 					log.Warn("Lists with more than two dimensions are not supported!")
+					t.Truncated = TruncatedDimensions
 					break
 				}
 
@@ -52,6 +66,20 @@ func reformatTypeRef(typeRef introspection.TypeRef) TypeRef {
 		a = a.OfType
 	}
 
+	if t.Name == "" && t.Truncated == "" {
+		t.Truncated = TruncatedDepth
+	}
+
+	return t
+}
+
+// reformatTypeRefAt reformats a type reference, and records it in the model when it is truncated.
+func (m *Model) reformatTypeRefAt(typeRef introspection.TypeRef, path string) TypeRef {
+	t := reformatTypeRef(typeRef)
+	if t.Truncated != "" {
+		m.Truncations = append(m.Truncations, Truncation{Path: path, Reason: t.Truncated})
+	}
+
 	return t
 }
 
@@ -62,7 +90,7 @@ func Reformat(model *introspection.Model) *Model {
 		return nil
 	}
 
-	types := make([]introspection.Type, len(model.Data.Schema.Types)-2)
+	types := make([]introspection.Type, 0, len(model.Data.Schema.Types))
 	var mutation, query introspection.Type
 
 	// Divide the types into a  mutation, a query, and the rest
@@ -87,13 +115,16 @@ func Reformat(model *introspection.Model) *Model {
 
 		arguments := make(map[string]TypeRef)
 		for _, a := range m.Arguments {
-			arguments[a.Name] = reformatTypeRef(a.Type)
+			if omitted(a) {
+				continue
+			}
+			arguments[a.Name] = reformatted.reformatTypeRefAt(a.Type, mutation.Name+"."+m.Name+"("+a.Name+")")
 		}
 
 		reformatted.Mutations[i] = Operation{
 			Name:      m.Name,
 			Arguments: arguments,
-			Type:      reformatTypeRef(m.Type),
+			Type:      reformatted.reformatTypeRefAt(m.Type, mutation.Name+"."+m.Name),
 		}
 	}
 
@@ -102,13 +133,16 @@ func Reformat(model *introspection.Model) *Model {
 
 		arguments := make(map[string]TypeRef)
 		for _, a := range q.Arguments {
-			arguments[a.Name] = reformatTypeRef(a.Type)
+			if omitted(a) {
+				continue
+			}
+			arguments[a.Name] = reformatted.reformatTypeRefAt(a.Type, query.Name+"."+q.Name+"("+a.Name+")")
 		}
 
 		reformatted.Queries[i] = Operation{
 			Name:      q.Name,
 			Arguments: arguments,
-			Type:      reformatTypeRef(q.Type),
+			Type:      reformatted.reformatTypeRefAt(q.Type, query.Name+"."+q.Name),
 		}
 	}
 
@@ -116,19 +150,26 @@ func Reformat(model *introspection.Model) *Model {
 	for _, t := range types {
 		rt := Type{
 			Name:          t.Name,
+			OneOf:         t.IsOneOf,
 			Fields:        make(map[string]TypeRef),
 			InputFields:   make(map[string]TypeRef),
 			EnumValues:    make([]string, 0),
 			PossibleTypes: make([]TypeRef, 0),
 		}
+		if t.SpecifiedByURL != nil {
+			rt.SpecifiedByURL = *t.SpecifiedByURL
+		}
 
 		for _, f := range t.Fields {
 			// Field arguments are skipped because they are only present for mutations and queries, not types.
-			rt.Fields[f.Name] = reformatTypeRef(f.Type)
+			rt.Fields[f.Name] = reformatted.reformatTypeRefAt(f.Type, t.Name+"."+f.Name)
 		}
 
 		for _, f := range t.InputFields {
-			rt.InputFields[f.Name] = reformatTypeRef(f.Type)
+			if omitted(f) {
+				continue
+			}
+			rt.InputFields[f.Name] = reformatted.reformatTypeRefAt(f.Type, t.Name+"."+f.Name)
 		}
 
 		for _, e := range t.EnumValues {
@@ -0,0 +1,39 @@
+package reformatted
+
+import (
+	"github.com/RobinCPel/graphql-postman/src/internal/graphql/sdl"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestReformatDeprecated(t *testing.T) {
+	raw, _, err := sdl.Parse(`
+		type Query { users(first: Int @deprecated, after: ID! @deprecated, filter: Filter): [String] }
+		input Filter { name: String @deprecated, role: String! @deprecated, born: String }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	model := Reformat(raw)
+
+	for _, tt := range []struct {
+		name   string
+		values map[string]TypeRef
+		want   []string
+	}{
+		{"arguments", model.Queries[0].Arguments, []string{"after", "filter"}},
+		{"input fields", model.Types["Filter"].InputFields, []string{"born", "role"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for name := range tt.values {
+				got = append(got, name)
+			}
+			sort.Strings(got)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v, only the deprecated ones that are not required are left out", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"strconv"
+)
+
+// maxResolvePasses is the number of times that the truncated types are introspected again, with a deeper query every time.
+const maxResolvePasses = 3
+
+// isTruncated returns whether a type reference ends in a list or a non-null, instead of in a named type,
+// which happens when it has more levels than the introspection query asked for.
+func isTruncated(typeRef TypeRef) bool {
+	t := &typeRef
+	for t.OfType != nil {
+		t = t.OfType
+	}
+
+	return t.Name == ""
+}
+
+// hasTruncated returns whether a type has a field, an argument, or an input field with a truncated type reference.
+func hasTruncated(t Type) bool {
+	for _, f := range t.Fields {
+		if isTruncated(f.Type) {
+			return true
+		}
+		for _, a := range f.Arguments {
+			if isTruncated(a.Type) {
+				return true
+			}
+		}
+	}
+	for _, f := range t.InputFields {
+		if isTruncated(f.Type) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// introspectTypes introspects the types with the given names, with depth levels of type references.
+func introspectTypes(ctx context.Context, endpoint, transport string, names []string, depth int, capabilities Capabilities, options Options) ([]Type, error) {
+	body, _, err := send(ctx, endpoint, transport, buildTypesQuery(names, depth, capabilities), options)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Errors []Error          `json:"errors"`
+		Data   map[string]*Type `json:"data"`
+	}
+	if err = json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Errors) > 0 {
+		return nil, &ResponseError{Errors: response.Errors}
+	}
+
+	types := make([]Type, len(names))
+	for i := range names {
+		t := response.Data["t"+strconv.Itoa(i)]
+		if t == nil {
+			return nil, errors.New(`the type "` + names[i] + `" could not be introspected`)
+		}
+		types[i] = *t
+	}
+
+	return types, nil
+}
+
+// resolveTruncated introspects the types with truncated type references again, with a deeper query, and replaces them
+// in the model. The types that can not be resolved are kept as they are, the reformatter reports their truncations.
+func resolveTruncated(ctx context.Context, endpoint, transport string, capabilities Capabilities, options Options, model *Model) {
+	depth := options.Depth
+	if depth == 0 {
+		depth = DefaultTypeRefDepth
+	}
+
+	for pass := 0; pass < maxResolvePasses; pass++ {
+		var names []string
+		index := make(map[string]int)
+		for i, t := range model.Data.Schema.Types {
+			if hasTruncated(t) {
+				names = append(names, t.Name)
+				index[t.Name] = i
+			}
+		}
+		if len(names) == 0 {
+			return
+		}
+
+		depth *= 2
+		log.Info("Introspecting " + strconv.Itoa(len(names)) + " types with truncated type references again, " +
+			strconv.Itoa(depth) + " levels deep...")
+
+		types, err := introspectTypes(ctx, endpoint, transport, names, depth, capabilities, options)
+		if err != nil {
+			log.WithError(err).Warning("failed to resolve the truncated type references")
+			return
+		}
+		for _, t := range types {
+			model.Data.Schema.Types[index[t.Name]] = t
+		}
+	}
+}
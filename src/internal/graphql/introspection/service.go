@@ -0,0 +1,52 @@
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// serviceQuery asks an Apollo Federation subgraph for its schema, in SDL.
+var serviceQuery = document{
+	Query: `query SubgraphSDL {
+  _service {
+    sdl
+  }
+}
+`,
+	OperationName: "SubgraphSDL",
+}
+
+// ServiceSDL fetches the schema of an Apollo Federation subgraph in SDL, with the _service field that every subgraph has.
+// Only the Client, Transport, Header, and Timeout of the options are used.
+func ServiceSDL(ctx context.Context, endpoint string, options Options) (string, error) {
+	transport := options.Transport
+	if transport == "" {
+		transport = TransportPost
+	}
+
+	body, _, err := send(ctx, endpoint, transport, serviceQuery, options)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Errors []Error `json:"errors"`
+		Data   struct {
+			Service *struct {
+				SDL string `json:"sdl"`
+			} `json:"_service"`
+		} `json:"data"`
+	}
+	if err = json.Unmarshal(body, &response); err != nil {
+		return "", err
+	}
+	if len(response.Errors) > 0 {
+		return "", &ResponseError{Errors: response.Errors}
+	}
+	if response.Data.Service == nil || response.Data.Service.SDL == "" {
+		return "", errors.New("the endpoint has no SDL in _service, it is not a federation subgraph")
+	}
+
+	return response.Data.Service.SDL, nil
+}
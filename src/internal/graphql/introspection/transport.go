@@ -0,0 +1,85 @@
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// The ways in which the introspection query can be sent.
+const (
+	TransportPost    = "post"    // A POST request with an application/json body
+	TransportGet     = "get"     // A GET request with the query and the variables in the url
+	TransportGraphQL = "graphql" // A POST request with the query as an application/graphql body
+)
+
+// fallbacks contains the transport to try next, when the endpoint responds to a transport with a status.
+var fallbacks = map[string]map[int]string{
+	TransportPost: {
+		http.StatusMethodNotAllowed:     TransportGet,
+		http.StatusUnsupportedMediaType: TransportGraphQL,
+	},
+	TransportGet: {
+		http.StatusMethodNotAllowed: TransportPost,
+	},
+	TransportGraphQL: {
+		http.StatusMethodNotAllowed:     TransportGet,
+		http.StatusUnsupportedMediaType: TransportPost,
+	},
+}
+
+// newRequest creates the request that sends a document with a transport, and with the headers.
+func newRequest(ctx context.Context, endpoint, transport string, doc document, header http.Header) (*http.Request, error) {
+	var req *http.Request
+	var err error
+	contentType := "application/json"
+
+	switch transport {
+
+	case TransportGet:
+		u, parseErr := url.Parse(endpoint)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		values := u.Query()
+		values.Set("query", doc.Query)
+		values.Set("variables", "{}")
+		values.Set("operationName", doc.OperationName)
+		u.RawQuery = values.Encode()
+
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		contentType = ""
+
+	case TransportGraphQL:
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(doc.Query))
+		contentType = "application/graphql"
+
+	default:
+		body, marshalErr := json.Marshal(map[string]interface{}{
+			"operationName": doc.OperationName,
+			"variables":     map[string]interface{}{},
+			"query":         doc.Query,
+		})
+		if marshalErr != nil {
+			return nil, marshalErr
+		}
+
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(string(body)))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for k, values := range header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return req, nil
+}
@@ -0,0 +1,29 @@
+package introspection
+
+// DefaultWordlist contains common names of fields, arguments, input fields, and enum values. The suggestions of the server
+// only contain names that look like the one that was tried, so a name that is close enough to a real one is a hit as well.
+var DefaultWordlist = []string{
+	// Fields of the root types
+	"me", "viewer", "node", "nodes", "search", "user", "users", "account", "accounts", "profile", "profiles",
+	"organization", "organizations", "team", "teams", "group", "groups", "member", "members", "role", "roles",
+	"project", "projects", "item", "items", "product", "products", "order", "orders", "customer", "customers",
+	"invoice", "invoices", "payment", "payments", "post", "posts", "comment", "comments", "message", "messages",
+	"file", "files", "event", "events", "task", "tasks", "setting", "settings", "config", "version", "health",
+	"login", "logout", "register", "signup", "token", "session", "upload",
+	"create", "update", "delete", "remove", "add", "set", "edit", "save",
+	"createUser", "updateUser", "deleteUser", "createPost", "updatePost", "deletePost",
+	"createOrder", "updateOrder", "deleteOrder", "createProduct", "updateProduct", "deleteProduct",
+
+	// Arguments and input fields
+	"id", "ids", "uuid", "key", "name", "title", "description", "email", "username", "password", "phone",
+	"type", "kind", "status", "state", "code", "value", "data", "input", "filter", "where", "query", "term",
+	"first", "last", "after", "before", "limit", "offset", "page", "perPage", "size", "cursor",
+	"sort", "sortBy", "orderBy", "direction", "order", "from", "to", "start", "end", "date",
+	"createdAt", "updatedAt", "deletedAt", "startDate", "endDate", "language", "locale", "country", "currency",
+	"price", "amount", "quantity", "count", "total", "tags", "category", "url", "content", "text", "body",
+	"active", "enabled", "public", "deleted", "archived", "firstName", "lastName", "address", "city", "userId",
+
+	// Enum values, every name is tried in upper case as well
+	"asc", "desc", "admin", "user", "guest", "owner", "editor", "viewer", "active", "inactive", "pending",
+	"open", "closed", "draft", "published", "archived", "enabled", "disabled", "none", "all", "other",
+}
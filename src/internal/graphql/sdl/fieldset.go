@@ -0,0 +1,51 @@
+package sdl
+
+// Selection is a field of a field set, with the fields that are selected on it.
+type Selection struct {
+	Name       string
+	Selections []Selection
+}
+
+// ParseFieldSet parses the field set of a @key, like "sku package { id }".
+func ParseFieldSet(fields string) ([]Selection, error) {
+	tokens, err := tokenize(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser{tokens: tokens}
+	selections, err := p.parseSelections()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.unexpected("a field")
+	}
+
+	return selections, nil
+}
+
+// parseSelections parses the fields of a field set, at least one.
+func (p *parser) parseSelections() ([]Selection, error) {
+	var selections []Selection
+	for p.peek().kind == tokenName {
+		s := Selection{Name: p.next().value}
+		if p.skip("{") {
+			nested, err := p.parseSelections()
+			if err != nil {
+				return nil, err
+			}
+			if err = p.expect("}"); err != nil {
+				return nil, err
+			}
+			s.Selections = nested
+		}
+		selections = append(selections, s)
+	}
+
+	if len(selections) == 0 {
+		return nil, p.unexpected("a field")
+	}
+
+	return selections, nil
+}
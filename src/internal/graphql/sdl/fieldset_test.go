@@ -0,0 +1,49 @@
+package sdl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFieldSet(t *testing.T) {
+	for _, tt := range []struct {
+		fields string
+		want   []Selection
+	}{
+		{"id", []Selection{{Name: "id"}}},
+		{"sku package { id }", []Selection{{Name: "sku"}, {Name: "package", Selections: []Selection{{Name: "id"}}}}},
+		{"a { b { c } d }, e", []Selection{{Name: "a", Selections: []Selection{{Name: "b", Selections: []Selection{{Name: "c"}}}, {Name: "d"}}}, {Name: "e"}}},
+		{"\n  id\n  organization { id }\n", []Selection{{Name: "id"}, {Name: "organization", Selections: []Selection{{Name: "id"}}}}},
+	} {
+		t.Run(tt.fields, func(t *testing.T) {
+			got, err := ParseFieldSet(tt.fields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFieldSetErrors(t *testing.T) {
+	for _, tt := range []struct {
+		fields string
+		want   string
+	}{
+		{"", "line 1: expected a field, found the end of the document"},
+		{"a {", "line 1: expected a field, found the end of the document"},
+		{"a { }", `line 1: expected a field, found "}"`},
+		{"a }", `line 1: expected a field, found "}"`},
+		{"a { b", `line 1: expected "}", found the end of the document`},
+		{`"id"`, "line 1: expected a field, found a string"},
+	} {
+		t.Run(tt.fields, func(t *testing.T) {
+			_, err := ParseFieldSet(tt.fields)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package sdl
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// The kinds of tokens in an SDL document.
+const (
+	tokenEOF = iota
+	tokenPunctuator
+	tokenName
+	tokenString
+	tokenNumber
+)
+
+// token is a single token of an SDL document.
+type token struct {
+	kind  int
+	value string // The text of the token, the contents of a string
+	line  int
+}
+
+// String describes the token for an error message.
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "the end of the document"
+	case tokenString:
+		return "a string"
+	default:
+		return `"` + t.value + `"`
+	}
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isNameContinue(c byte) bool {
+	return isNameStart(c) || c >= '0' && c <= '9'
+}
+
+// blockString returns the value of a block string, without the indentation that all of its lines have, except for the
+// first one, and without the blank lines at the start and at the end.
+func blockString(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(strings.ReplaceAll(raw, "\r\n", "\n"), "\r", "\n"), "\n")
+
+	indent := -1
+	for _, l := range lines[1:] {
+		trimmed := strings.TrimLeft(l, " \t")
+		if trimmed != "" && (indent < 0 || len(l)-len(trimmed) < indent) {
+			indent = len(l) - len(trimmed)
+		}
+	}
+	if indent > 0 {
+		for i, l := range lines[1:] {
+			if len(l) < indent {
+				lines[i+1] = ""
+			} else {
+				lines[i+1] = l[indent:]
+			}
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimLeft(lines[0], " \t") == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// tokenize splits an SDL document into tokens. Whitespace, commas, and comments are left out.
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	line := 1
+
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+
+		case c == '\n':
+			line++
+			i++
+
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			i++
+
+		case c == '#':
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+
+		case strings.HasPrefix(source[i:], "..."):
+			tokens = append(tokens, token{kind: tokenPunctuator, value: "...", line: line})
+			i += 3
+
+		case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+			tokens = append(tokens, token{kind: tokenPunctuator, value: string(c), line: line})
+			i++
+
+		case isNameStart(c):
+			start := i
+			for i < len(source) && isNameContinue(source[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenName, value: source[start:i], line: line})
+
+		case c == '-' || c >= '0' && c <= '9':
+			start := i
+			i++
+			for i < len(source) && strings.IndexByte("0123456789.eE+-", source[i]) >= 0 {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: source[start:i], line: line})
+
+		case strings.HasPrefix(source[i:], `"""`):
+			end := strings.Index(strings.ReplaceAll(source[i+3:], `\"""`, `\xxx`), `"""`)
+			if end < 0 {
+				return nil, errors.New("line " + strconv.Itoa(line) + ": the block string does not end")
+			}
+			value := source[i+3 : i+3+end]
+			tokens = append(tokens, token{kind: tokenString, value: blockString(strings.ReplaceAll(value, `\"""`, `"""`)), line: line})
+			line += strings.Count(value, "\n")
+			i += end + 6
+
+		case c == '"':
+			end := i + 1
+			for end < len(source) && source[end] != '"' && source[end] != '\n' {
+				if source[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(source) || source[end] != '"' {
+				return nil, errors.New("line " + strconv.Itoa(line) + ": the string does not end")
+			}
+
+			// The escape sequences of GraphQL are the ones of Go, next to an escaped slash
+			value, err := strconv.Unquote(strings.ReplaceAll(source[i:end+1], `\/`, `/`))
+			if err != nil {
+				return nil, errors.New("line " + strconv.Itoa(line) + ": the string is not valid: " + err.Error())
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value, line: line})
+			i = end + 1
+
+		default:
+			return nil, errors.New("line " + strconv.Itoa(line) + `: unexpected character "` + string(c) + `"`)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, line: line}), nil
+}
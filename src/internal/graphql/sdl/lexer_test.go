@@ -0,0 +1,84 @@
+package sdl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		source string
+		want   []token
+	}{
+		{
+			name:   "skips whitespace, commas, and comments",
+			source: "type Query {\n  # a comment\n  a, b: Int!\n}",
+			want: []token{
+				{tokenName, "type", 1}, {tokenName, "Query", 1}, {tokenPunctuator, "{", 1},
+				{tokenName, "a", 3}, {tokenName, "b", 3}, {tokenPunctuator, ":", 3}, {tokenName, "Int", 3},
+				{tokenPunctuator, "!", 3}, {tokenPunctuator, "}", 4}, {tokenEOF, "", 4},
+			},
+		},
+		{
+			name:   "numbers and the spread",
+			source: "-1.5e3 0 ...",
+			want:   []token{{tokenNumber, "-1.5e3", 1}, {tokenNumber, "0", 1}, {tokenPunctuator, "...", 1}, {tokenEOF, "", 1}},
+		},
+		{
+			name:   "escape sequences in strings",
+			source: `"a \"quote\", a \/ slash, and é"`,
+			want:   []token{{tokenString, `a "quote", a / slash, and é`, 1}, {tokenEOF, "", 1}},
+		},
+		{
+			name:   "an escaped block string quote in a block string",
+			source: `"""Use \""" to quote""" name`,
+			want:   []token{{tokenString, `Use """ to quote`, 1}, {tokenName, "name", 1}, {tokenEOF, "", 1}},
+		},
+		{
+			name:   "a block string that ends with an escaped block string quote",
+			source: `"""ends with \""""""`,
+			want:   []token{{tokenString, `ends with """`, 1}, {tokenEOF, "", 1}},
+		},
+		{
+			name:   "the common indentation and the blank lines of a block string are removed",
+			source: "\"\"\"\n    The first line\n      indented\n\n    the last line\n  \"\"\"\ntype",
+			want:   []token{{tokenString, "The first line\n  indented\n\nthe last line", 1}, {tokenName, "type", 7}, {tokenEOF, "", 7}},
+		},
+		{
+			name:   "a block string can contain single quotes and escapes as they are",
+			source: `"""a "quote" and \n"""`,
+			want:   []token{{tokenString, `a "quote" and \n`, 1}, {tokenEOF, "", 1}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tokenize(tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	for _, tt := range []struct {
+		source string
+		want   string
+	}{
+		{"type Query {\n  a: Int ?\n}", `line 2: unexpected character "?"`},
+		{"\n\"not closed\ntype", "line 2: the string does not end"},
+		{`"not closed`, "line 1: the string does not end"},
+		{`"\x"`, "line 1: the string is not valid: invalid syntax"},
+		{"\n\"\"\"not closed \\\"\"\"", "line 2: the block string does not end"},
+	} {
+		t.Run(tt.source, func(t *testing.T) {
+			_, err := tokenize(tt.source)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,691 @@
+package sdl
+
+import (
+	"errors"
+	"github.com/RobinCPel/graphql-postman/src/internal/graphql/introspection"
+	"strconv"
+	"strings"
+)
+
+// Entity is an object type with a @key, which a federation subgraph can fetch by its keys with the _entities query.
+type Entity struct {
+	Name   string
+	Keys   []string // The field sets of the resolvable keys, like "id" or "sku package { id }"
+	Fields []string // The fields that the subgraph resolves itself, and that can be selected without arguments
+}
+
+// directive is a directive that is applied to a definition, with its arguments.
+type directive struct {
+	name      string
+	arguments map[string]string // Strings without their quotes, the other values as they are written, lists and objects are empty
+}
+
+// inputValue is an argument, or a field of an input object.
+type inputValue struct {
+	name       string
+	typ        string // The type in GraphQL notation, like "[User!]!"
+	hasDefault bool
+	deprecated bool
+}
+
+// field is a field of an object or an interface.
+type field struct {
+	name       string
+	arguments  []inputValue
+	typ        string // The type in GraphQL notation, like "[User!]!"
+	external   bool   // Whether the field is resolved by another subgraph
+	deprecated bool
+}
+
+// definition is a type, with everything that its definition and its extensions define.
+type definition struct {
+	kind           string
+	fields         []field
+	inputFields    []inputValue
+	enumValues     []string
+	possibleTypes  []string
+	interfaces     []string // The interfaces that an object or an interface implements
+	specifiedByURL *string
+	oneOf          bool
+	keys           []string
+}
+
+// parser parses the tokens of an SDL document.
+type parser struct {
+	tokens      []token
+	pos         int
+	definitions map[string]*definition
+	order       []string          // The names of the definitions, in the order that they are in the document
+	roots       map[string]string // The names of the root types, by operation
+	directives  []introspection.Directive
+}
+
+// federationName returns the name of a federation directive without its namespace, which it has in subgraphs
+// that do not import it, like "key" for "federation__key".
+func federationName(name string) string {
+	return strings.TrimPrefix(name, "federation__")
+}
+
+// isDeprecated returns whether the directives contain @deprecated.
+func isDeprecated(directives []directive) bool {
+	for _, d := range directives {
+		if d.name == "deprecated" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Parse parses the SDL of a schema to an introspection model, like the one that introspecting the schema would return,
+// and returns the federation entities in it. The extensions of a type are merged into the type.
+func Parse(source string) (*introspection.Model, []Entity, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := parser{
+		tokens:      tokens,
+		definitions: make(map[string]*definition),
+		roots:       make(map[string]string),
+	}
+	for p.peek().kind != tokenEOF {
+		if err = p.parseDefinition(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	model, entities := p.model()
+	return model, entities, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+// skip skips the next token when it is a punctuator or a name with the value, and returns whether it did.
+func (p *parser) skip(value string) bool {
+	t := p.peek()
+	if (t.kind == tokenPunctuator || t.kind == tokenName) && t.value == value {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+// unexpected returns the error about a token that is not what was expected.
+func (p *parser) unexpected(expected string) error {
+	t := p.peek()
+	return errors.New("line " + strconv.Itoa(t.line) + ": expected " + expected + ", found " + t.String())
+}
+
+func (p *parser) expect(value string) error {
+	if !p.skip(value) {
+		return p.unexpected(`"` + value + `"`)
+	}
+
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	if p.peek().kind != tokenName {
+		return "", p.unexpected("a name")
+	}
+
+	return p.next().value, nil
+}
+
+// description skips the description of a definition, if there is one.
+func (p *parser) description() {
+	if p.peek().kind == tokenString {
+		p.next()
+	}
+}
+
+// definition returns the definition of a type, which is created when it does not exist yet.
+func (p *parser) definition(name, kind string) *definition {
+	d, ok := p.definitions[name]
+	if !ok {
+		d = &definition{kind: kind}
+		p.definitions[name] = d
+		p.order = append(p.order, name)
+	}
+
+	return d
+}
+
+// parseDefinition parses a definition, or an extension, of the schema, a type, or a directive.
+func (p *parser) parseDefinition() error {
+	p.description()
+	p.skip("extend")
+
+	line := p.peek().line
+	keyword, err := p.name()
+	if err != nil {
+		return err
+	}
+
+	switch keyword {
+	case "schema":
+		return p.parseSchema()
+	case "scalar":
+		return p.parseScalar()
+	case "type":
+		return p.parseObject("OBJECT")
+	case "interface":
+		return p.parseObject("INTERFACE")
+	case "union":
+		return p.parseUnion()
+	case "enum":
+		return p.parseEnum()
+	case "input":
+		return p.parseInput()
+	case "directive":
+		return p.parseDirectiveDefinition()
+	}
+
+	return errors.New("line " + strconv.Itoa(line) + `: unknown definition "` + keyword + `"`)
+}
+
+func (p *parser) parseSchema() error {
+	if _, err := p.parseDirectives(); err != nil {
+		return err
+	}
+	if !p.skip("{") {
+		return nil
+	}
+
+	for !p.skip("}") {
+		operation, err := p.name()
+		if err != nil {
+			return err
+		}
+		if err = p.expect(":"); err != nil {
+			return err
+		}
+		if p.roots[operation], err = p.name(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *parser) parseScalar() error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	directives, err := p.parseDirectives()
+	if err != nil {
+		return err
+	}
+
+	d := p.definition(name, "SCALAR")
+	for _, dir := range directives {
+		if dir.name == "specifiedBy" {
+			url := dir.arguments["url"]
+			d.specifiedByURL = &url
+		}
+	}
+
+	return nil
+}
+
+// parseObject parses an object type or an interface, the kind is either "OBJECT" or "INTERFACE".
+func (p *parser) parseObject(kind string) error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	d := p.definition(name, kind)
+
+	// The objects that implement an interface are its possible types
+	if p.skip("implements") {
+		p.skip("&")
+		for {
+			i, err := p.name()
+			if err != nil {
+				return err
+			}
+			d.interfaces = append(d.interfaces, i)
+			if !p.skip("&") {
+				break
+			}
+		}
+	}
+
+	directives, err := p.parseDirectives()
+	if err != nil {
+		return err
+	}
+	for _, dir := range directives {
+		if federationName(dir.name) == "key" && dir.arguments["resolvable"] != "false" {
+			d.keys = append(d.keys, dir.arguments["fields"])
+		}
+	}
+
+	if !p.skip("{") {
+		return nil
+	}
+	for !p.skip("}") {
+		f, err := p.parseField()
+		if err != nil {
+			return err
+		}
+		d.fields = append(d.fields, f)
+	}
+
+	return nil
+}
+
+func (p *parser) parseField() (field, error) {
+	var f field
+	var err error
+
+	p.description()
+	if f.name, err = p.name(); err != nil {
+		return f, err
+	}
+	if p.skip("(") {
+		for !p.skip(")") {
+			argument, err := p.parseInputValue()
+			if err != nil {
+				return f, err
+			}
+			f.arguments = append(f.arguments, argument)
+		}
+	}
+	if err = p.expect(":"); err != nil {
+		return f, err
+	}
+	if f.typ, err = p.parseType(); err != nil {
+		return f, err
+	}
+
+	directives, err := p.parseDirectives()
+	if err != nil {
+		return f, err
+	}
+	for _, dir := range directives {
+		if federationName(dir.name) == "external" {
+			f.external = true
+		}
+	}
+	f.deprecated = isDeprecated(directives)
+
+	return f, nil
+}
+
+func (p *parser) parseUnion() error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	if _, err = p.parseDirectives(); err != nil {
+		return err
+	}
+
+	d := p.definition(name, "UNION")
+	if !p.skip("=") {
+		return nil
+	}
+	p.skip("|")
+	for {
+		member, err := p.name()
+		if err != nil {
+			return err
+		}
+		d.possibleTypes = append(d.possibleTypes, member)
+		if !p.skip("|") {
+			return nil
+		}
+	}
+}
+
+func (p *parser) parseEnum() error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	if _, err = p.parseDirectives(); err != nil {
+		return err
+	}
+
+	d := p.definition(name, "ENUM")
+	if !p.skip("{") {
+		return nil
+	}
+	for !p.skip("}") {
+		p.description()
+		value, err := p.name()
+		if err != nil {
+			return err
+		}
+		directives, err := p.parseDirectives()
+		if err != nil {
+			return err
+		}
+
+		// Like the introspection query, deprecated enum values are left out
+		if !isDeprecated(directives) {
+			d.enumValues = append(d.enumValues, value)
+		}
+	}
+
+	return nil
+}
+
+func (p *parser) parseInput() error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	directives, err := p.parseDirectives()
+	if err != nil {
+		return err
+	}
+
+	d := p.definition(name, "INPUT_OBJECT")
+	for _, dir := range directives {
+		if dir.name == "oneOf" {
+			d.oneOf = true
+		}
+	}
+
+	if !p.skip("{") {
+		return nil
+	}
+	for !p.skip("}") {
+		inputField, err := p.parseInputValue()
+		if err != nil {
+			return err
+		}
+		d.inputFields = append(d.inputFields, inputField)
+	}
+
+	return nil
+}
+
+func (p *parser) parseDirectiveDefinition() error {
+	if err := p.expect("@"); err != nil {
+		return err
+	}
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	if p.skip("(") {
+		for !p.skip(")") {
+			if _, err = p.parseInputValue(); err != nil {
+				return err
+			}
+		}
+	}
+
+	d := introspection.Directive{Named: introspection.Named{Name: name}, IsRepeatable: p.skip("repeatable")}
+	if err = p.expect("on"); err != nil {
+		return err
+	}
+	p.skip("|")
+	for {
+		location, err := p.name()
+		if err != nil {
+			return err
+		}
+		d.Locations = append(d.Locations, location)
+		if !p.skip("|") {
+			break
+		}
+	}
+	p.directives = append(p.directives, d)
+
+	return nil
+}
+
+// parseInputValue parses an argument, or a field of an input object, the default value is skipped.
+func (p *parser) parseInputValue() (inputValue, error) {
+	var v inputValue
+	var err error
+
+	p.description()
+	if v.name, err = p.name(); err != nil {
+		return v, err
+	}
+	if err = p.expect(":"); err != nil {
+		return v, err
+	}
+	if v.typ, err = p.parseType(); err != nil {
+		return v, err
+	}
+	if v.hasDefault = p.skip("="); v.hasDefault {
+		if _, err = p.parseValue(); err != nil {
+			return v, err
+		}
+	}
+
+	directives, err := p.parseDirectives()
+	if err != nil {
+		return v, err
+	}
+	v.deprecated = isDeprecated(directives)
+
+	return v, nil
+}
+
+// parseType parses a type, and returns it in GraphQL notation, like "[User!]!".
+func (p *parser) parseType() (string, error) {
+	var typ string
+	var err error
+
+	if p.skip("[") {
+		if typ, err = p.parseType(); err != nil {
+			return "", err
+		}
+		if err = p.expect("]"); err != nil {
+			return "", err
+		}
+		typ = "[" + typ + "]"
+	} else if typ, err = p.name(); err != nil {
+		return "", err
+	}
+
+	if p.skip("!") {
+		typ += "!"
+	}
+
+	return typ, nil
+}
+
+// parseValue parses a value, strings are returned without their quotes, lists and objects are returned empty.
+func (p *parser) parseValue() (string, error) {
+	t := p.peek()
+	switch {
+
+	case t.kind == tokenName || t.kind == tokenNumber || t.kind == tokenString:
+		p.next()
+		return t.value, nil
+
+	case p.skip("$"):
+		name, err := p.name()
+		return "$" + name, err
+
+	case p.skip("["):
+		for !p.skip("]") {
+			if _, err := p.parseValue(); err != nil {
+				return "", err
+			}
+		}
+		return "", nil
+
+	case p.skip("{"):
+		for !p.skip("}") {
+			if _, err := p.name(); err != nil {
+				return "", err
+			}
+			if err := p.expect(":"); err != nil {
+				return "", err
+			}
+			if _, err := p.parseValue(); err != nil {
+				return "", err
+			}
+		}
+		return "", nil
+	}
+
+	return "", p.unexpected("a value")
+}
+
+// parseDirectives parses the directives that are applied to a definition, if there are any.
+func (p *parser) parseDirectives() ([]directive, error) {
+	var directives []directive
+	for p.skip("@") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+
+		d := directive{name: name, arguments: make(map[string]string)}
+		if p.skip("(") {
+			for !p.skip(")") {
+				argument, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				if err = p.expect(":"); err != nil {
+					return nil, err
+				}
+				if d.arguments[argument], err = p.parseValue(); err != nil {
+					return nil, err
+				}
+			}
+		}
+		directives = append(directives, d)
+	}
+
+	return directives, nil
+}
+
+// typeRef converts a type in GraphQL notation to a type reference.
+// Types that are not defined, like the _Any of federation, are scalars.
+func (p *parser) typeRef(typ string) introspection.TypeRef {
+	if strings.HasSuffix(typ, "!") {
+		ofType := p.typeRef(strings.TrimSuffix(typ, "!"))
+		return introspection.TypeRef{Kind: "NON_NULL", OfType: &ofType}
+	}
+	if strings.HasPrefix(typ, "[") && strings.HasSuffix(typ, "]") {
+		ofType := p.typeRef(typ[1 : len(typ)-1])
+		return introspection.TypeRef{Kind: "LIST", OfType: &ofType}
+	}
+
+	kind := "SCALAR"
+	if d, ok := p.definitions[typ]; ok {
+		kind = d.kind
+	}
+	return introspection.TypeRef{Named: introspection.Named{Name: typ}, Kind: kind}
+}
+
+// inputValue converts an argument, or a field of an input object, to the one of the introspection model.
+func (p *parser) inputValue(v inputValue) introspection.NamedTypeRef {
+	return introspection.NamedTypeRef{Named: introspection.Named{Name: v.name}, Type: p.typeRef(v.typ), IsDeprecated: v.deprecated}
+}
+
+// resolvedFields returns the names of the fields of an entity that its subgraph resolves, without arguments.
+func resolvedFields(fields []field) []string {
+	var names []string
+	for _, f := range fields {
+		required := false
+		for _, a := range f.arguments {
+			required = required || strings.HasSuffix(a.typ, "!") && !a.hasDefault
+		}
+		if !f.external && !f.deprecated && !required {
+			names = append(names, f.name)
+		}
+	}
+
+	return names
+}
+
+// model returns the introspection model of the parsed definitions, and the entities among them.
+func (p *parser) model() (*introspection.Model, []Entity) {
+	query, mutation := p.roots["query"], p.roots["mutation"]
+	if query == "" && p.definitions["Query"] != nil {
+		query = "Query"
+	}
+	if mutation == "" && p.definitions["Mutation"] != nil {
+		mutation = "Mutation"
+	}
+
+	var model introspection.Model
+	schema := &model.Data.Schema
+	schema.QueryType.Name = query
+	schema.MutationType.Name = mutation
+	schema.Directives = p.directives
+
+	// Every schema has the built-in scalars, also when the SDL does not define them
+	for _, name := range []string{"String", "Int", "Float", "Boolean", "ID"} {
+		p.definition(name, "SCALAR")
+	}
+
+	implementations := make(map[string][]string)
+	for _, name := range p.order {
+		if d := p.definitions[name]; d.kind == "OBJECT" {
+			for _, i := range d.interfaces {
+				implementations[i] = append(implementations[i], name)
+			}
+		}
+	}
+
+	var entities []Entity
+	for _, name := range p.order {
+		d := p.definitions[name]
+		root := name == query || name == mutation
+
+		t := introspection.Type{Named: introspection.Named{Name: name}, SpecifiedByURL: d.specifiedByURL, IsOneOf: d.oneOf}
+		for _, f := range d.fields {
+			// Like the introspection query, deprecated fields are left out. The root types only keep the fields that
+			// the subgraph resolves itself, so not the external ones, nor the ones that federation adds
+			if f.deprecated || root && (f.external || f.name == "_service" || f.name == "_entities") {
+				continue
+			}
+
+			typeField := introspection.TypeField{Named: introspection.Named{Name: f.name}, Type: p.typeRef(f.typ)}
+			for _, a := range f.arguments {
+				typeField.Arguments = append(typeField.Arguments, p.inputValue(a))
+			}
+			t.Fields = append(t.Fields, typeField)
+		}
+		for _, f := range d.inputFields {
+			t.InputFields = append(t.InputFields, p.inputValue(f))
+		}
+		for _, v := range d.enumValues {
+			t.EnumValues = append(t.EnumValues, introspection.Named{Name: v})
+		}
+		for _, member := range d.possibleTypes {
+			t.PossibleTypes = append(t.PossibleTypes, p.typeRef(member))
+		}
+		if d.kind == "INTERFACE" {
+			for _, object := range implementations[name] {
+				t.PossibleTypes = append(t.PossibleTypes, p.typeRef(object))
+			}
+		}
+		schema.Types = append(schema.Types, t)
+
+		if d.kind == "OBJECT" && len(d.keys) > 0 {
+			entities = append(entities, Entity{Name: name, Keys: d.keys, Fields: resolvedFields(d.fields)})
+		}
+	}
+
+	return &model, entities
+}
@@ -0,0 +1,265 @@
+package sdl
+
+import (
+	"github.com/RobinCPel/graphql-postman/src/internal/graphql/introspection"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		source   string
+		want     []string // See describe
+		entities []Entity
+	}{
+		{
+			name: "merges extensions into the type, whichever comes first",
+			source: `
+				extend type User { email: String }
+				type Query { user(id: ID!): User }
+				type User { id: ID! }
+				extend type Query { users(first: Int = 10): [User!]! }
+				extend schema { mutation: Changes }
+				type Changes { rename(name: String!): User }`,
+			want: []string{
+				"Changes.rename(name: String!): User",
+				"Query.user(id: ID!): User",
+				"Query.users(first: Int): [User!]!",
+				"User.email: String",
+				"User.id: ID!",
+			},
+		},
+		{
+			name: "skips descriptions, also with an escaped block string quote in them",
+			source: `
+				"""
+				A user, which is written as \"""user\""" in the docs
+				"""
+				type Query {
+					"The user with the id"
+					user("""The \""" id""" id: ID!): String
+				}`,
+			want: []string{"Query.user(id: ID!): String"},
+		},
+		{
+			name: "leaves out deprecated fields and enum values",
+			source: `
+				type Query { role: Role, old: String @deprecated(reason: "use role") }
+				enum Role { ADMIN MEMBER GUEST @deprecated }`,
+			want: []string{"Query.role: Role", "Role = ADMIN | MEMBER"},
+		},
+		{
+			name: "input objects, unions, scalars, and directives",
+			source: `
+				scalar DateTime @specifiedBy(url: "https://scalars.graphql.org/andimarek/date-time")
+				input Filter @oneOf { name: String, born: DateTime }
+				union Result = | User | Error
+				type User { id: ID! }
+				type Error { message: String! }
+				directive @auth(requires: [String!] = ["ADMIN"]) repeatable on FIELD_DEFINITION | OBJECT
+				type Query { search(filter: Filter!): [Result] }`,
+			want: []string{
+				"DateTime specified by https://scalars.graphql.org/andimarek/date-time",
+				"Error.message: String!",
+				"Filter is one of",
+				"Filter.born: DateTime",
+				"Filter.name: String",
+				"Query.search(filter: Filter!): [Result]",
+				"Result = User | Error",
+				"User.id: ID!",
+				"directive @auth repeatable on FIELD_DEFINITION | OBJECT",
+			},
+		},
+		{
+			name: "the objects that implement an interface are its possible types",
+			source: `
+				interface Node { id: ID! }
+				interface Named implements Node { id: ID!, name: String }
+				type User implements Node & Named { id: ID!, name: String }
+				type Team implements & Node { id: ID! }
+				extend type Team implements Named { name: String }
+				type Query { node(id: ID!): Node }`,
+			want: []string{
+				"Named = User | Team",
+				"Named.id: ID!",
+				"Named.name: String",
+				"Node = User | Team",
+				"Node.id: ID!",
+				"Query.node(id: ID!): Node",
+				"Team.id: ID!",
+				"Team.name: String",
+				"User.id: ID!",
+				"User.name: String",
+			},
+		},
+		{
+			name: "the resolvable keys of federation entities, also with the namespace of federation",
+			source: `
+				extend schema @link(url: "https://specs.apollo.dev/federation/v2.3", import: ["@shareable"])
+				type Product @federation__key(fields: "upc") @federation__key(fields: "sku package { id }") {
+					upc: String!
+					sku: String!
+					package: Package!
+					price(currency: String!): Int
+					reviews(first: Int! = 5): [Review]
+					weight: Int @deprecated
+				}
+				type Package { id: ID! }
+				type Review @key(fields: "id", resolvable: false) { id: ID! }
+				type Query {
+					product(upc: String!): Product
+					_service: _Service!
+					_entities(representations: [_Any!]!): [_Entity]!
+				}`,
+			want: []string{
+				"Package.id: ID!",
+				"Product.package: Package!",
+				"Product.price(currency: String!): Int",
+				"Product.reviews(first: Int!): [Review]",
+				"Product.sku: String!",
+				"Product.upc: String!",
+				"Query.product(upc: String!): Product",
+				"Review.id: ID!",
+			},
+			entities: []Entity{{Name: "Product", Keys: []string{"upc", "sku package { id }"}, Fields: []string{"upc", "sku", "package", "reviews"}}},
+		},
+		{
+			name: "the external fields of the root types are resolved by another subgraph",
+			source: `
+				extend type Query { me: User @external, reviews: [Review] }
+				extend type User @key(fields: "id") { id: ID! @federation__external, reviews: [Review] }
+				type Review { body: String }`,
+			want: []string{
+				"Query.reviews: [Review]",
+				"Review.body: String",
+				"User.id: ID!",
+				"User.reviews: [Review]",
+			},
+			entities: []Entity{{Name: "User", Keys: []string{"id"}, Fields: []string{"reviews"}}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			model, entities, err := Parse(tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := describe(model); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if !reflect.DeepEqual(entities, tt.entities) {
+				t.Errorf("got the entities %+v, want %+v", entities, tt.entities)
+			}
+		})
+	}
+}
+
+func TestParseRoots(t *testing.T) {
+	for _, tt := range []struct {
+		source   string
+		query    string
+		mutation string
+	}{
+		{"type Query { a: Int } type Mutation { b: Int }", "Query", "Mutation"},
+		{"schema { query: Root } type Root { a: Int } type Query { b: Int }", "Root", ""},
+		{"type Other { a: Int }", "", ""},
+	} {
+		t.Run(tt.source, func(t *testing.T) {
+			model, _, err := Parse(tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			schema := model.Data.Schema
+			if schema.QueryType.Name != tt.query || schema.MutationType.Name != tt.mutation {
+				t.Errorf("got the roots %q and %q, want %q and %q", schema.QueryType.Name, schema.MutationType.Name, tt.query, tt.mutation)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tt := range []struct {
+		source string
+		want   string
+	}{
+		{"type Query {\n  a Int\n}", `line 2: expected ":", found "Int"`},
+		{"type Query {\n  a: Int", "line 2: expected a name, found the end of the document"},
+		{"\nquery { a }", `line 2: unknown definition "query"`},
+		{"type Query @key(fields: ) { a: Int }", `line 1: expected a value, found ")"`},
+		{"directive @auth in OBJECT", `line 1: expected "on", found "in"`},
+	} {
+		t.Run(tt.source, func(t *testing.T) {
+			_, _, err := Parse(tt.source)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// describe returns a line for every field, input field, enum, union, and directive of a model, sorted.
+// The built-in scalars and the types without anything in them are left out.
+func describe(model *introspection.Model) []string {
+	var lines []string
+	for _, typ := range model.Data.Schema.Types {
+		for _, f := range typ.Fields {
+			var arguments []string
+			for _, a := range f.Arguments {
+				arguments = append(arguments, a.Name+": "+notation(a.Type))
+			}
+			line := typ.Name + "." + f.Name
+			if arguments != nil {
+				line += "(" + strings.Join(arguments, ", ") + ")"
+			}
+			lines = append(lines, line+": "+notation(f.Type))
+		}
+		for _, f := range typ.InputFields {
+			lines = append(lines, typ.Name+"."+f.Name+": "+notation(f.Type))
+		}
+		if typ.EnumValues != nil {
+			var values []string
+			for _, v := range typ.EnumValues {
+				values = append(values, v.Name)
+			}
+			lines = append(lines, typ.Name+" = "+strings.Join(values, " | "))
+		}
+		if typ.PossibleTypes != nil {
+			var names []string
+			for _, p := range typ.PossibleTypes {
+				names = append(names, p.Name)
+			}
+			lines = append(lines, typ.Name+" = "+strings.Join(names, " | "))
+		}
+		if typ.SpecifiedByURL != nil {
+			lines = append(lines, typ.Name+" specified by "+*typ.SpecifiedByURL)
+		}
+		if typ.IsOneOf {
+			lines = append(lines, typ.Name+" is one of")
+		}
+	}
+	for _, d := range model.Data.Schema.Directives {
+		line := "directive @" + d.Name
+		if d.IsRepeatable {
+			line += " repeatable"
+		}
+		lines = append(lines, line+" on "+strings.Join(d.Locations, " | "))
+	}
+	sort.Strings(lines)
+
+	return lines
+}
+
+// notation returns a type reference in GraphQL notation.
+func notation(ref introspection.TypeRef) string {
+	switch ref.Kind {
+	case "NON_NULL":
+		return notation(*ref.OfType) + "!"
+	case "LIST":
+		return "[" + notation(*ref.OfType) + "]"
+	}
+
+	return ref.Name
+}
@@ -0,0 +1,39 @@
+package graphqlfiles
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/RobinCPel/graphql-postman/src/internal/postman"
+)
+
+// CreateFiles returns a "<OperationName>.graphql" file with the query, and a "<OperationName>.variables.json"
+// file with the variables, for every GqlInput. The key of the returned map is the name of the file.
+func CreateFiles(gql []postman.GqlInput) (map[string][]byte, error) {
+	files := make(map[string][]byte, len(gql)*2)
+
+	for _, entry := range gql {
+		name := entry.OperationName
+		if name == "" {
+			name = entry.Name
+		}
+
+		query := "# The " + entry.OperationType + ` "` + entry.Operation.Name + `", generated by graphql-postman` + "\n" +
+			entry.Query + "\n"
+		files[name+".graphql"] = []byte(query)
+
+		variables := entry.Variables
+		if variables == "" {
+			variables = "{}"
+		}
+
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, []byte(variables), "", "    "); err != nil {
+			return nil, errors.New(`the variables of "` + name + `" are not valid json: ` + err.Error())
+		}
+		buf.WriteString("\n")
+		files[name+".variables.json"] = buf.Bytes()
+	}
+
+	return files, nil
+}
@@ -0,0 +1,191 @@
+package har
+
+import (
+	"github.com/RobinCPel/graphql-postman/src/internal/postman"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Creator struct {
+	Name    string `json:"name"`    // Always "graphql-postman"
+	Version string `json:"version"` // Always "1.0"
+}
+
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type PostData struct {
+	MimeType string `json:"mimeType"` // Always "application/json"
+	Text     string `json:"text"`     // The Payload of a GqlInput
+}
+
+type Request struct {
+	Method      string      `json:"method"` // Always "POST"
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"` // Always "HTTP/1.1"
+	Cookies     []NameValue `json:"cookies"`     // Always empty
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    PostData    `json:"postData"`
+	HeadersSize int         `json:"headersSize"` // Always -1, unknown
+	BodySize    int         `json:"bodySize"`
+}
+
+type Content struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"` // Always "HTTP/1.1"
+	Cookies     []NameValue `json:"cookies"`     // Always empty
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"` // Always empty
+	HeadersSize int         `json:"headersSize"` // Always -1, unknown
+	BodySize    int         `json:"bodySize"`
+	Error       string      `json:"_error,omitempty"` // Why there is no response, like browsers write for failed requests
+}
+
+type Timings struct {
+	Send    int `json:"send"`    // Always 0
+	Wait    int `json:"wait"`    // Always 0
+	Receive int `json:"receive"` // Always 0
+}
+
+type Entry struct {
+	StartedDateTime string   `json:"startedDateTime"`
+	Time            int      `json:"time"` // Always 0
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"` // The first example of the GqlInput, see createResponse
+	Cache           struct{} `json:"cache"`    // Always empty
+	Timings         Timings  `json:"timings"`
+	Comment         string   `json:"comment,omitempty"` // The operation name
+}
+
+type Log struct {
+	Version string  `json:"version"` // Always "1.2"
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+// Har represents a HAR 1.2 document, with an entry for every GqlInput.
+type Har struct {
+	Log Log `json:"log"`
+}
+
+// Config contains everything that can be configured about a generated HAR document.
+type Config struct {
+	URL                 string      // The URL every request is sent to
+	Headers             []NameValue // Headers that every request is sent with, e.g. the authorization header
+	OperationNameHeader string      // The name of a header that every request sends its operation name in, optional
+}
+
+// createResponse returns the response of an entry, which is the first example of the GqlInput if it has any.
+// Without one, the response has status 0 and an _error, which is how browsers write a request that got no response.
+func createResponse(entry postman.GqlInput) Response {
+	response := Response{
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []NameValue{},
+		Headers:     []NameValue{},
+		Content:     Content{MimeType: "x-unknown"},
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+
+	if len(entry.Examples) == 0 {
+		response.Error = "no response is recorded, use -examples or -live to add one"
+		return response
+	}
+	example := entry.Examples[0]
+
+	response.Status = example.Status
+	response.StatusText = http.StatusText(example.Status)
+	response.BodySize = len(example.Body)
+	response.Content = Content{Size: len(example.Body), MimeType: "application/json", Text: example.Body}
+	for _, h := range example.Header {
+		response.Headers = append(response.Headers, NameValue{Name: h.Key, Value: h.Value})
+		if strings.EqualFold(h.Key, "Content-Type") {
+			response.Content.MimeType = h.Value
+		}
+	}
+
+	return response
+}
+
+// createQueryString returns the query string of a URL in the notation of a HAR document.
+func createQueryString(rawURL string) []NameValue {
+	queryString := []NameValue{}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return queryString
+	}
+
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		for _, v := range query[k] {
+			queryString = append(queryString, NameValue{Name: k, Value: v})
+		}
+	}
+
+	return queryString
+}
+
+// CreateHar returns a HAR document with a POST request for every GqlInput.
+func CreateHar(gql []postman.GqlInput, config Config) (*Har, error) {
+	startedDateTime := time.Now().UTC().Format(time.RFC3339)
+	entries := make([]Entry, len(gql))
+
+	for i, entry := range gql {
+		payload, err := entry.Payload()
+		if err != nil {
+			return nil, err
+		}
+
+		headers := []NameValue{{Name: "Content-Type", Value: "application/json"}}
+		headers = append(headers, config.Headers...)
+		if config.OperationNameHeader != "" && entry.OperationName != "" {
+			headers = append(headers, NameValue{Name: config.OperationNameHeader, Value: entry.OperationName})
+		}
+
+		entries[i] = Entry{
+			StartedDateTime: startedDateTime,
+			Request: Request{
+				Method:      "POST",
+				URL:         config.URL,
+				HTTPVersion: "HTTP/1.1",
+				Cookies:     []NameValue{},
+				Headers:     headers,
+				QueryString: createQueryString(config.URL),
+				PostData:    PostData{MimeType: "application/json", Text: payload},
+				HeadersSize: -1,
+				BodySize:    len(payload),
+			},
+			Response: createResponse(entry),
+			Comment:  entry.OperationName,
+		}
+	}
+
+	return &Har{
+		Log: Log{
+			Version: "1.2",
+			Creator: Creator{Name: "graphql-postman", Version: "1.0"},
+			Entries: entries,
+		},
+	}, nil
+}
@@ -0,0 +1,108 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Config contains everything that can be configured about the connections to the endpoint.
+type Config struct {
+	CAFile             string // A PEM bundle with CA certificates to trust next to the system ones, optional
+	CertFile           string // A PEM client certificate for mTLS, optional, requires KeyFile
+	KeyFile            string // The PEM private key of the client certificate, optional, requires CertFile
+	ServerName         string // The name to verify the certificate of the server with, instead of the host, optional
+	InsecureSkipVerify bool   // Whether the certificate of the server is not verified at all
+	Proxy              string // The URL of an http, https, or socks5 proxy, optional, HTTP_PROXY, HTTPS_PROXY, and NO_PROXY are used otherwise
+}
+
+// proxySchemes contains the schemes of the proxies that are supported.
+var proxySchemes = map[string]bool{"http": true, "https": true, "socks5": true}
+
+// tlsConfig creates the TLS config of the transport.
+func tlsConfig(config Config) (*tls.Config, error) {
+	c := &tls.Config{
+		ServerName:         config.ServerName,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if config.CAFile != "" {
+		pem, err := ioutil.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New(`no certificates could be read from "` + config.CAFile + `"`)
+		}
+		c.RootCAs = pool
+	}
+
+	if (config.CertFile == "") != (config.KeyFile == "") {
+		return nil, errors.New("a client certificate needs both a certificate file and a key file")
+	}
+	if config.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		c.Certificates = []tls.Certificate{cert}
+	}
+
+	return c, nil
+}
+
+// proxyTLSConfig creates the TLS config of the connection to an https proxy. It trusts the same certificates as the
+// connection to the endpoint, so that an intercepting proxy can be trusted with the CAFile, but the certificate of
+// the proxy is verified with the host of the proxy, not with the ServerName, and it gets no client certificate.
+func proxyTLSConfig(c *tls.Config) *tls.Config {
+	return &tls.Config{
+		RootCAs:            c.RootCAs,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+}
+
+// New creates an HTTP client with the given config, on top of the defaults of http.DefaultTransport.
+//
+// The connection to an https Proxy has a TLS config of its own, see proxyTLSConfig. A proxy from the HTTPS_PROXY
+// environment variable does not, Go uses the TLS config of the endpoint for it, including the ServerName.
+func New(config Config) (*http.Client, error) {
+	c, err := tlsConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = c
+
+	if config.Proxy != "" {
+		proxy, err := url.Parse(config.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		if !proxySchemes[proxy.Scheme] || proxy.Host == "" {
+			return nil, errors.New(`proxy "` + config.Proxy + `" is not an http, https, or socks5 url`)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+
+		// Go only dials the proxy with DialTLSContext, the TLS connection to the endpoint through the proxy still uses the TLSClientConfig
+		if proxy.Scheme == "https" {
+			dialer := &tls.Dialer{
+				NetDialer: &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+				Config:    proxyTLSConfig(c),
+			}
+			transport.DialTLSContext = dialer.DialContext
+		}
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
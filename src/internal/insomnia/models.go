@@ -0,0 +1,123 @@
+package insomnia
+
+import (
+	"github.com/RobinCPel/graphql-postman/src/internal/postman"
+	"strconv"
+	"time"
+)
+
+// Resource types
+const (
+	typeWorkspace    = "workspace"
+	typeEnvironment  = "environment"
+	typeRequestGroup = "request_group"
+	typeRequest      = "request"
+)
+
+type Header struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Body struct {
+	MimeType string `json:"mimeType"` // Always "application/graphql"
+	Text     string `json:"text"`     // The Payload of a GqlInput
+}
+
+type Authentication struct {
+	Type  string `json:"type,omitempty"`  // Either "bearer" or empty
+	Token string `json:"token,omitempty"` // Always "{{ _.token }}"
+}
+
+// Resource is a workspace, environment, request group (folder), or request.
+// Dependent on the type, certain fields are filled, while others are left empty.
+type Resource struct {
+	ID             string            `json:"_id"`
+	Type           string            `json:"_type"`
+	ParentID       *string           `json:"parentId"` // Only nil for the workspace
+	Name           string            `json:"name"`
+	Description    string            `json:"description,omitempty"`
+	Scope          string            `json:"scope,omitempty"`  // Only for the workspace
+	Data           map[string]string `json:"data,omitempty"`   // Only for the environment
+	Method         string            `json:"method,omitempty"` // Only for requests, always "POST"
+	URL            string            `json:"url,omitempty"`    // Only for requests, always "{{ _.baseUrl }}"
+	Body           *Body             `json:"body,omitempty"`
+	Headers        []Header          `json:"headers,omitempty"`
+	Authentication *Authentication   `json:"authentication,omitempty"`
+}
+
+// Export represents an Insomnia v4 export, with a workspace that contains all of the GqlInputs.
+type Export struct {
+	Type         string     `json:"_type"`           // Always "export"
+	ExportFormat int        `json:"__export_format"` // Always 4
+	ExportDate   string     `json:"__export_date"`
+	ExportSource string     `json:"__export_source"` // Always "graphql-postman"
+	Resources    []Resource `json:"resources"`
+}
+
+// CreateExport returns an Insomnia export with the same folders, URL, and auth as the Postman Collection that
+// would be created with the same config. The URL and token are stored in the base environment of the workspace.
+func CreateExport(gql []postman.GqlInput, config postman.Config) (*Export, error) {
+	workspaceID := "wrk_1"
+	environment := map[string]string{"baseUrl": config.URL}
+	if config.Token != "" {
+		environment["token"] = config.Token
+	}
+
+	resources := []Resource{
+		{ID: workspaceID, Type: typeWorkspace, Name: config.Name, Scope: "collection"},
+		{ID: "env_1", Type: typeEnvironment, ParentID: &workspaceID, Name: "Base Environment", Data: environment},
+	}
+
+	var requestCount int
+	for i, folder := range postman.GroupInputs(gql, config.Folders) {
+		parentID := workspaceID
+		if folder.Name != "" {
+			parentID = "fld_" + strconv.Itoa(i+1)
+			resources = append(resources, Resource{ID: parentID, Type: typeRequestGroup, ParentID: &workspaceID, Name: folder.Name})
+		}
+
+		for _, entry := range folder.Inputs {
+			payload, err := entry.Payload()
+			if err != nil {
+				return nil, err
+			}
+
+			headers := []Header{{Name: "Content-Type", Value: "application/json"}}
+			for _, h := range config.Headers {
+				headers = append(headers, Header{Name: h.Key, Value: h.Value})
+			}
+			if config.OperationNameHeader != "" && entry.OperationName != "" {
+				headers = append(headers, Header{Name: config.OperationNameHeader, Value: entry.OperationName})
+			}
+
+			auth := Authentication{}
+			if config.Token != "" {
+				auth = Authentication{Type: "bearer", Token: "{{ _.token }}"}
+			}
+
+			requestCount++
+			requestParentID := parentID
+			resources = append(resources, Resource{
+				ID:             "req_" + strconv.Itoa(requestCount),
+				Type:           typeRequest,
+				ParentID:       &requestParentID,
+				Name:           entry.Name,
+				Description:    "The " + entry.OperationType + ` "` + entry.Operation.Name + `"`,
+				Method:         "POST",
+				URL:            "{{ _.baseUrl }}",
+				Body:           &Body{MimeType: "application/graphql", Text: payload},
+				Headers:        headers,
+				Authentication: &auth,
+			})
+		}
+	}
+
+	return &Export{
+		Type:         "export",
+		ExportFormat: 4,
+		ExportDate:   time.Now().UTC().Format(time.RFC3339),
+		ExportSource: "graphql-postman",
+		Resources:    resources,
+	}, nil
+}
@@ -0,0 +1,55 @@
+package jmeter
+
+import (
+	"encoding/xml"
+	"strconv"
+)
+
+// element is a generic XML element, a JMeter test plan consists of only a few kinds of elements
+// (test elements, properties, and hash trees), which are all created with the functions below.
+type element struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Text     string     `xml:",chardata"`
+	Children []element
+}
+
+// newElement returns an element with the given attributes, in "name", "value", "name", "value" order.
+func newElement(tag string, attrs []string, children ...element) element {
+	e := element{XMLName: xml.Name{Local: tag}, Children: children}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		e.Attrs = append(e.Attrs, xml.Attr{Name: xml.Name{Local: attrs[i]}, Value: attrs[i+1]})
+	}
+
+	return e
+}
+
+// testElement returns a test element, e.g. a ThreadGroup, with its properties as children.
+func testElement(testClass, guiClass, testName string, props ...element) element {
+	return newElement(testClass, []string{"guiclass", guiClass, "testclass", testClass, "testname", testName, "enabled", "true"}, props...)
+}
+
+// hashTree returns a hash tree, which contains the children of the test element that precedes it.
+func hashTree(children ...element) element {
+	return newElement("hashTree", nil, children...)
+}
+
+func stringProp(name, value string) element {
+	e := newElement("stringProp", []string{"name", name})
+	e.Text = value
+	return e
+}
+
+func boolProp(name string, value bool) element {
+	e := newElement("boolProp", []string{"name", name})
+	e.Text = strconv.FormatBool(value)
+	return e
+}
+
+func elementProp(name, elementType string, props ...element) element {
+	return newElement("elementProp", []string{"name", name, "elementType", elementType}, props...)
+}
+
+func collectionProp(name string, props ...element) element {
+	return newElement("collectionProp", []string{"name", name}, props...)
+}
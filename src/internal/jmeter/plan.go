@@ -0,0 +1,156 @@
+package jmeter
+
+import (
+	"encoding/xml"
+	"github.com/RobinCPel/graphql-postman/src/internal/postman"
+	"net/url"
+	"strings"
+)
+
+// Config contains everything that can be configured about a generated JMeter test plan.
+type Config struct {
+	Name                string           // The name of the test plan
+	URL                 string           // The default URL of the GraphQL endpoint
+	Token               string           // The default bearer token, no auth when empty
+	Headers             []postman.Header // Headers that every request is sent with
+	OperationNameHeader string           // The name of a header that every request sends its operation name in, optional
+}
+
+// functionArgumentEscaper escapes the characters that have a special meaning in an argument of a JMeter function.
+var functionArgumentEscaper = strings.NewReplacer(`\`, `\\`, `,`, `\,`)
+
+// variable returns a user defined variable, that can be overridden with a JMeter property, e.g. "-Jgraphql.host=...".
+func variable(name, property, value string) element {
+	return elementProp(name, "Argument",
+		stringProp("Argument.name", name),
+		stringProp("Argument.value", "${__P("+property+","+functionArgumentEscaper.Replace(value)+")}"),
+		stringProp("Argument.metadata", "="),
+	)
+}
+
+// headerManager returns an HTTP Header Manager with the given headers.
+func headerManager(testName string, headers []postman.Header) element {
+	elements := make([]element, len(headers))
+	for i, h := range headers {
+		elements[i] = elementProp("", "Header", stringProp("Header.name", h.Key), stringProp("Header.value", h.Value))
+	}
+
+	return testElement("HeaderManager", "HeaderPanel", testName, collectionProp("HeaderManager.headers", elements...))
+}
+
+// sampler returns a GraphQL HTTP Request sampler that sends a GqlInput.
+func sampler(entry postman.GqlInput) (element, error) {
+	payload, err := entry.Payload()
+	if err != nil {
+		return element{}, err
+	}
+
+	return testElement("GraphQLHTTPSamplerProxy", "GraphQLHTTPSamplerGui", entry.Name,
+		stringProp("GraphQLHTTPSampler.operationName", entry.OperationName),
+		stringProp("GraphQLHTTPSampler.query", entry.Query),
+		stringProp("GraphQLHTTPSampler.variables", entry.Variables),
+		boolProp("HTTPSampler.postBodyRaw", true),
+		elementProp("HTTPsampler.Arguments", "Arguments", collectionProp("Arguments.arguments",
+			elementProp("", "HTTPArgument",
+				boolProp("HTTPArgument.always_encode", false),
+				stringProp("Argument.value", payload),
+				stringProp("Argument.metadata", "="),
+			),
+		)),
+		stringProp("HTTPSampler.domain", "${GRAPHQL_HOST}"),
+		stringProp("HTTPSampler.port", "${GRAPHQL_PORT}"),
+		stringProp("HTTPSampler.protocol", "${GRAPHQL_PROTOCOL}"),
+		stringProp("HTTPSampler.path", "${GRAPHQL_PATH}"),
+		stringProp("HTTPSampler.method", "POST"),
+		boolProp("HTTPSampler.follow_redirects", true),
+		boolProp("HTTPSampler.use_keepalive", true),
+	), nil
+}
+
+// CreatePlan returns a JMeter test plan, with a Thread Group that has a GraphQL HTTP Request sampler for
+// every GqlInput. Every response is asserted to have no "errors" field. The URL and token are user defined
+// variables, which can be overridden with the "graphql.protocol", "graphql.host", "graphql.port",
+// "graphql.path", and "graphql.token" JMeter properties.
+func CreatePlan(gql []postman.GqlInput, config Config) ([]byte, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+
+	variables := []element{
+		variable("GRAPHQL_PROTOCOL", "graphql.protocol", u.Scheme),
+		variable("GRAPHQL_HOST", "graphql.host", u.Hostname()),
+		variable("GRAPHQL_PORT", "graphql.port", port),
+		variable("GRAPHQL_PATH", "graphql.path", u.RequestURI()),
+	}
+
+	headers := []postman.Header{{Key: "Content-Type", Value: "application/json"}}
+	if config.Token != "" {
+		variables = append(variables, variable("GRAPHQL_TOKEN", "graphql.token", config.Token))
+		headers = append(headers, postman.Header{Key: "Authorization", Value: "Bearer ${GRAPHQL_TOKEN}"})
+	}
+	headers = append(headers, config.Headers...)
+
+	threadGroupTree := []element{
+		headerManager("HTTP Header Manager", headers),
+		hashTree(),
+		testElement("JSONPathAssertion", "JSONPathAssertionGui", "No GraphQL errors",
+			stringProp("JSON_PATH", "$.errors"),
+			stringProp("EXPECTED_VALUE", ""),
+			boolProp("JSONVALIDATION", false),
+			boolProp("EXPECT_NULL", false),
+			boolProp("INVERT", true),
+			boolProp("ISREGEX", false),
+		),
+		hashTree(),
+	}
+
+	for _, entry := range gql {
+		s, err := sampler(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		samplerTree := hashTree()
+		if config.OperationNameHeader != "" && entry.OperationName != "" {
+			samplerTree = hashTree(
+				headerManager("Operation Name Header", []postman.Header{{Key: config.OperationNameHeader, Value: entry.OperationName}}),
+				hashTree(),
+			)
+		}
+		threadGroupTree = append(threadGroupTree, s, samplerTree)
+	}
+
+	plan := newElement("jmeterTestPlan", []string{"version", "1.2", "properties", "5.0", "jmeter", "5.6.3"}, hashTree(
+		testElement("TestPlan", "TestPlanGui", config.Name,
+			elementProp("TestPlan.user_defined_variables", "Arguments", collectionProp("Arguments.arguments", variables...)),
+		),
+		hashTree(
+			testElement("ThreadGroup", "ThreadGroupGui", "GraphQL Operations",
+				stringProp("ThreadGroup.on_sample_error", "continue"),
+				elementProp("ThreadGroup.main_controller", "LoopController",
+					boolProp("LoopController.continue_forever", false),
+					stringProp("LoopController.loops", "1"),
+				),
+				stringProp("ThreadGroup.num_threads", "1"),
+				stringProp("ThreadGroup.ramp_time", "1"),
+			),
+			hashTree(threadGroupTree...),
+		),
+	))
+
+	data, err := xml.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
@@ -0,0 +1,28 @@
+package jmeter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreatePlanEscapesTheDefaults(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{"a comma in the token", Config{URL: "http://localhost/gql", Token: "a,b"}, `${__P(graphql.token,a\,b)}`},
+		{"a backslash in the token", Config{URL: "http://localhost/gql", Token: `a\,b\`}, `${__P(graphql.token,a\\\,b\\)}`},
+		{"a comma in the path", Config{URL: "http://localhost/api,v2/gql"}, `${__P(graphql.path,/api\,v2/gql)}`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := CreatePlan(nil, tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(data), tt.want) {
+				t.Errorf("the test plan does not contain %s:\n%s", tt.want, data)
+			}
+		})
+	}
+}
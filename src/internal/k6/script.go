@@ -0,0 +1,97 @@
+package k6
+
+import (
+	"encoding/json"
+	"github.com/RobinCPel/graphql-postman/src/internal/postman"
+	"strconv"
+	"strings"
+)
+
+// Config contains everything that can be configured about a generated k6 script.
+type Config struct {
+	URL                 string           // The default URL of the GraphQL endpoint, when GRAPHQL_URL is not set
+	Token               string           // The default bearer token, when GRAPHQL_TOKEN is not set, optional
+	VUs                 int              // The number of virtual users
+	Duration            string           // How long the test runs, e.g. "30s"
+	Headers             []postman.Header // Headers that every request is sent with
+	OperationNameHeader string           // The name of a header that every request sends its operation name in, optional
+}
+
+// literal returns a string, or a map of strings, as a JavaScript literal.
+// Every JSON value is a valid JavaScript literal, and these can always be marshalled.
+func literal(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// CreateScript returns a k6 load test script, that sends every GqlInput once per iteration,
+// and checks that none of the responses has GraphQL errors.
+func CreateScript(gql []postman.GqlInput, config Config) ([]byte, error) {
+	headers := map[string]string{"Content-Type": "application/json"}
+	for _, h := range config.Headers {
+		headers[h.Key] = h.Value
+	}
+
+	token := "optional"
+	if config.Token != "" {
+		token = "by default the one that the script was generated with"
+	}
+
+	var script strings.Builder
+	script.WriteString(`// Generated by graphql-postman, the requests can be configured with these environment variables:
+//
+// GRAPHQL_URL    The URL of the GraphQL endpoint, by default: ` + config.URL + `
+// GRAPHQL_TOKEN  The bearer token to send with every request, ` + token + `
+//
+// Run it with: k6 run <this file>
+import http from 'k6/http';
+import { check } from 'k6';
+
+export const options = {
+  vus: ` + strconv.Itoa(config.VUs) + `,
+  duration: ` + literal(config.Duration) + `,
+};
+
+const url = __ENV.GRAPHQL_URL || ` + literal(config.URL) + `;
+const token = __ENV.GRAPHQL_TOKEN || ` + literal(config.Token) + `;
+const headers = ` + literal(headers) + `;
+if (token) {
+  headers['Authorization'] = 'Bearer ' + token;
+}
+
+// noErrors checks that a response is a GraphQL response without errors.
+function noErrors(r) {
+  try {
+    return !r.json('errors');
+  } catch (e) {
+    return false;
+  }
+}
+
+export default function () {
+  let res;
+`)
+
+	for _, entry := range gql {
+		payload, err := entry.Payload()
+		if err != nil {
+			return nil, err
+		}
+
+		requestHeaders := `headers`
+		if config.OperationNameHeader != "" && entry.OperationName != "" {
+			requestHeaders = `Object.assign({}, headers, ` + literal(map[string]string{config.OperationNameHeader: entry.OperationName}) + `)`
+		}
+		tags := `{ name: ` + literal(entry.Name) + ` }`
+
+		script.WriteString(`
+  // ` + entry.OperationName + `: ` + entry.OperationType + ` ` + entry.Operation.Signature() + `
+  res = http.post(url, ` + literal(payload) + `, { headers: ` + requestHeaders + `, tags: ` + tags + ` });
+  check(res, { ` + literal(entry.Name+" has no errors") + `: noErrors }, ` + tags + `);
+`)
+	}
+
+	script.WriteString("}\n")
+
+	return []byte(script.String()), nil
+}
@@ -0,0 +1,92 @@
+package openapi
+
+import "encoding/json"
+
+// Schema is a JSON Schema object, as far as OpenAPI 3.0 supports it.
+type Schema struct {
+	Ref           string             `json:"$ref,omitempty"`
+	Type          string             `json:"type,omitempty"`
+	Format        string             `json:"format,omitempty"`
+	Description   string             `json:"description,omitempty"`
+	Nullable      bool               `json:"nullable,omitempty"`
+	Items         *Schema            `json:"items,omitempty"`
+	Properties    map[string]*Schema `json:"properties,omitempty"`
+	Required      []string           `json:"required,omitempty"`
+	MinProperties int                `json:"minProperties,omitempty"`
+	MaxProperties int                `json:"maxProperties,omitempty"`
+	Enum          []string           `json:"enum,omitempty"`
+	OneOf         []*Schema          `json:"oneOf,omitempty"`
+	AllOf         []*Schema          `json:"allOf,omitempty"` // Only to make a $ref nullable, OpenAPI 3.0 ignores the siblings of a $ref
+}
+
+type Example struct {
+	Summary string          `json:"summary"`
+	Value   json.RawMessage `json:"value"` // The Payload of a GqlInput
+}
+
+type MediaType struct {
+	Schema   *Schema            `json:"schema"`
+	Examples map[string]Example `json:"examples,omitempty"` // The key is the operation name
+}
+
+type RequestBody struct {
+	Description string               `json:"description"`
+	Required    bool                 `json:"required"` // Always true
+	Content     map[string]MediaType `json:"content"`  // Always only "application/json"
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"` // Always "header"
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+	Example  string  `json:"example,omitempty"`
+}
+
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Description string              `json:"description"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody RequestBody         `json:"requestBody"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type PathItem struct {
+	Post Operation `json:"post"`
+}
+
+type SecurityScheme struct {
+	Type   string `json:"type"`   // Always "http"
+	Scheme string `json:"scheme"` // Always "bearer"
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type Info struct {
+	Title   string `json:"title"`   // Configurable, by default: "GraphQL Postman"
+	Version string `json:"version"` // Always "1.0.0"
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+// Document represents an OpenAPI 3.0 document, that describes the GraphQL endpoint
+// as a single POST endpoint, with a request example for every GqlInput.
+type Document struct {
+	OpenAPI    string                `json:"openapi"` // Always "3.0.3"
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers"`
+	Paths      map[string]PathItem   `json:"paths"` // Only has the path of the GraphQL endpoint
+	Components Components            `json:"components"`
+	Security   []map[string][]string `json:"security,omitempty"`
+}
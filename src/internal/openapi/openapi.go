@@ -0,0 +1,318 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/RobinCPel/graphql-postman/src/internal/graphql/introspection/reformatted"
+	"github.com/RobinCPel/graphql-postman/src/internal/graphql/kind"
+	"github.com/RobinCPel/graphql-postman/src/internal/graphql/scalar"
+	"github.com/RobinCPel/graphql-postman/src/internal/postman"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Config contains everything that can be configured about a generated OpenAPI document.
+type Config struct {
+	Title               string            // The title of the document
+	URL                 string            // The URL of the GraphQL endpoint
+	Bearer              bool              // Whether the endpoint requires a bearer token
+	Headers             map[string]string // Headers that every request is sent with, the key is the name
+	OperationNameHeader string            // The name of a header that every request sends its operation name in, optional
+}
+
+// schemaOfScalar returns the JSON Schema of a scalar, custom scalars can be anything.
+func schemaOfScalar(name string) *Schema {
+	switch strings.ToLower(name) {
+	case scalar.Integer:
+		return &Schema{Type: "integer", Format: "int32"}
+	case scalar.Float:
+		return &Schema{Type: "number", Format: "double"}
+	case scalar.String:
+		return &Schema{Type: "string"}
+	case scalar.Boolean:
+		return &Schema{Type: "boolean"}
+	case scalar.ID:
+		return &Schema{Type: "string"}
+	}
+
+	return &Schema{Description: `Custom scalar "` + name + `"`}
+}
+
+// isRequired returns whether the outermost type of a type reference is non-nullable.
+func isRequired(typeRef reformatted.TypeRef) bool {
+	if typeRef.TwoDList {
+		return typeRef.TwoDListNonNull
+	}
+	if typeRef.List {
+		return typeRef.ListNonNull
+	}
+	return typeRef.NonNull
+}
+
+// nullable returns a schema that allows null as well.
+func nullable(schema *Schema) *Schema {
+	if schema.Ref != "" {
+		return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+	}
+
+	// Custom scalars allow anything already
+	if schema.Type != "" {
+		schema.Nullable = true
+	}
+	return schema
+}
+
+// requestComponent returns the name of the component with the request schema of an operation. It has a prefix with a
+// dot, which GraphQL names can not have, so that it never has the name of one of the input types in the components.
+func requestComponent(operationName string) string {
+	return "Operation." + operationName
+}
+
+// schemaBuilder builds the JSON Schemas of input types, and keeps track of
+// the input object and enum types that need to be added to the components.
+type schemaBuilder struct {
+	types      map[string]reformatted.Type
+	components map[string]*Schema
+}
+
+// component adds the schema of an input object or enum type to the components, if it is not there yet.
+func (b *schemaBuilder) component(name, typeKind string) error {
+	if _, ok := b.components[name]; ok {
+		return nil
+	}
+
+	t, ok := b.types[name]
+	if !ok {
+		return errors.New(`could not find the type "` + name + `" in the types map`)
+	}
+
+	if typeKind == kind.Enum {
+		b.components[name] = &Schema{Type: "string", Enum: t.EnumValues}
+		return nil
+	}
+
+	// Add it before the fields, so recursive input objects do not loop forever
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	b.components[name] = schema
+
+	for fieldName, field := range t.InputFields {
+		// The fields of a @oneOf input object are nullable, but the one that is set can not be null
+		if t.OneOf {
+			field = nonNull(field)
+		}
+
+		fieldSchema, err := b.schemaOf(field)
+		if err != nil {
+			return err
+		}
+		schema.Properties[fieldName] = fieldSchema
+
+		if isRequired(field) && !t.OneOf {
+			schema.Required = append(schema.Required, fieldName)
+		}
+	}
+	sort.Strings(schema.Required)
+
+	// Exactly one of the fields of a @oneOf input object is set
+	if t.OneOf {
+		schema.MinProperties, schema.MaxProperties = 1, 1
+		for _, fieldName := range sortedKeys(schema.Properties) {
+			schema.OneOf = append(schema.OneOf, &Schema{Required: []string{fieldName}})
+		}
+	}
+
+	return nil
+}
+
+// nonNull returns a type reference of which the outermost type is non-nullable.
+func nonNull(typeRef reformatted.TypeRef) reformatted.TypeRef {
+	switch {
+	case typeRef.TwoDList:
+		typeRef.TwoDListNonNull = true
+	case typeRef.List:
+		typeRef.ListNonNull = true
+	default:
+		typeRef.NonNull = true
+	}
+
+	return typeRef
+}
+
+// sortedKeys returns the keys of a map of schemas in alphabetical order.
+func sortedKeys(m map[string]*Schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// schemaOf returns the JSON Schema of an input type reference.
+func (b *schemaBuilder) schemaOf(typeRef reformatted.TypeRef) (*Schema, error) {
+	var schema *Schema
+
+	switch k := strings.ToLower(typeRef.Kind); k {
+	case kind.Scalar:
+		schema = schemaOfScalar(typeRef.Name)
+	case kind.Enum, kind.InputObject:
+		if err := b.component(typeRef.Name, k); err != nil {
+			return nil, err
+		}
+		schema = &Schema{Ref: "#/components/schemas/" + typeRef.Name}
+	default:
+		return nil, errors.New(`type of kind "` + typeRef.Kind + `" can not be an input`)
+	}
+
+	if !typeRef.NonNull {
+		schema = nullable(schema)
+	}
+	if typeRef.List {
+		schema = &Schema{Type: "array", Items: schema, Nullable: !typeRef.ListNonNull}
+	}
+	if typeRef.TwoDList {
+		schema = &Schema{Type: "array", Items: schema, Nullable: !typeRef.TwoDListNonNull}
+	}
+
+	return schema, nil
+}
+
+// schemaOfVariables returns the JSON Schema of the variables of an operation.
+func (b *schemaBuilder) schemaOfVariables(o reformatted.Operation) (*Schema, error) {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for name, argument := range o.Arguments {
+		argumentSchema, err := b.schemaOf(argument)
+		if err != nil {
+			return nil, errors.New(`argument "` + name + `": ` + err.Error())
+		}
+		schema.Properties[name] = argumentSchema
+
+		if isRequired(argument) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+
+	return schema, nil
+}
+
+// splitURL splits the URL of the GraphQL endpoint into the URL of the server and the path.
+func splitURL(rawURL string) (string, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	u.Path, u.RawPath, u.RawQuery, u.Fragment = "", "", "", ""
+
+	return u.String(), path, nil
+}
+
+// CreateDocument returns an OpenAPI document that has a request example and
+// a request schema for every GqlInput, with the variables as JSON Schema.
+func CreateDocument(gql []postman.GqlInput, types map[string]reformatted.Type, config Config) (*Document, error) {
+	server, path, err := splitURL(config.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	b := schemaBuilder{types: types, components: make(map[string]*Schema)}
+	examples := make(map[string]Example, len(gql))
+	requests := make([]*Schema, 0, len(gql))
+
+	for _, entry := range gql {
+		payload, err := entry.Payload()
+		if err != nil {
+			return nil, err
+		}
+
+		variables, err := b.schemaOfVariables(entry.Operation)
+		if err != nil {
+			return nil, errors.New(`could not create the variables schema of "` + entry.OperationName + `": ` + err.Error())
+		}
+
+		// The request schema of an operation only allows its own query
+		requestName := requestComponent(entry.OperationName)
+		b.components[requestName] = &Schema{
+			Type:        "object",
+			Description: "The " + entry.OperationType + ` "` + entry.Operation.Name + `"`,
+			Properties: map[string]*Schema{
+				"query":         {Type: "string", Enum: []string{entry.Query}},
+				"operationName": {Type: "string", Enum: []string{entry.OperationName}},
+				"variables":     variables,
+			},
+			Required: []string{"query"},
+		}
+		requests = append(requests, &Schema{Ref: "#/components/schemas/" + requestName})
+
+		examples[entry.OperationName] = Example{
+			Summary: entry.OperationType + " " + entry.Operation.Name,
+			Value:   json.RawMessage(payload),
+		}
+	}
+
+	operation := Operation{
+		OperationID: "graphql",
+		Summary:     "GraphQL endpoint",
+		Description: "Every GraphQL query and mutation is sent to this endpoint, there is an example for each of them.",
+		RequestBody: RequestBody{
+			Description: "A GraphQL request",
+			Required:    true,
+			Content: map[string]MediaType{
+				"application/json": {Schema: &Schema{OneOf: requests}, Examples: examples},
+			},
+		},
+		Responses: map[string]Response{
+			"200": {
+				Description: "A GraphQL response, with data and/or errors",
+				Content: map[string]MediaType{
+					"application/json": {Schema: &Schema{Type: "object"}},
+				},
+			},
+		},
+	}
+
+	headerNames := make([]string, 0, len(config.Headers))
+	for name := range config.Headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	for _, name := range headerNames {
+		operation.Parameters = append(operation.Parameters, Parameter{
+			Name:     name,
+			In:       "header",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+			Example:  config.Headers[name],
+		})
+	}
+	if config.OperationNameHeader != "" {
+		operation.Parameters = append(operation.Parameters, Parameter{
+			Name:   config.OperationNameHeader,
+			In:     "header",
+			Schema: &Schema{Type: "string"},
+		})
+	}
+
+	doc := Document{
+		OpenAPI:    "3.0.3",
+		Info:       Info{Title: config.Title, Version: "1.0.0"},
+		Servers:    []Server{{URL: server}},
+		Paths:      map[string]PathItem{path: {Post: operation}},
+		Components: Components{Schemas: b.components},
+	}
+
+	if config.Bearer {
+		doc.Components.SecuritySchemes = map[string]SecurityScheme{"bearerAuth": {Type: "http", Scheme: "bearer"}}
+		doc.Security = []map[string][]string{{"bearerAuth": {}}}
+	}
+
+	return &doc, nil
+}
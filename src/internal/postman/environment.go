@@ -0,0 +1,48 @@
+package postman
+
+type EnvironmentValue struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Type    string `json:"type"` // Either "default" or "secret"
+	Enabled bool   `json:"enabled"`
+}
+
+// Environment represents a Postman Environment, which contains the values
+// of the variables that a collection refers to, e.g. "{{baseUrl}}".
+type Environment struct {
+	ID     string             `json:"id"`   // Configurable, by default: "00000000-0000-0000-0000-000000000000"
+	Name   string             `json:"name"` // Configurable, by default: "GraphQL Postman"
+	Values []EnvironmentValue `json:"values"`
+	Scope  string             `json:"_postman_variable_scope"` // Always "environment"
+}
+
+// Variable is a single variable of an environment.
+type Variable struct {
+	Key    string
+	Value  string
+	Secret bool // Secrets are hidden in the Postman UI
+}
+
+// CreateEnvironment returns an environment that contains all of the given variables.
+func CreateEnvironment(variables []Variable, id, name string) Environment {
+	env := Environment{
+		ID:     id,
+		Name:   name,
+		Values: make([]EnvironmentValue, len(variables)),
+		Scope:  "environment",
+	}
+
+	for i, v := range variables {
+		env.Values[i] = EnvironmentValue{
+			Key:     v.Key,
+			Value:   v.Value,
+			Type:    "default",
+			Enabled: true,
+		}
+		if v.Secret {
+			env.Values[i].Type = "secret"
+		}
+	}
+
+	return env
+}
@@ -0,0 +1,41 @@
+package postman
+
+// Folder is a group of GqlInputs, which ends up as a folder in a collection.
+type Folder struct {
+	Name   string // Empty for the GqlInputs that are not in a folder
+	Inputs []GqlInput
+}
+
+// folderNames contains the name of the folder for every operation type.
+var folderNames = map[string]string{
+	"query":    "Queries",
+	"mutation": "Mutations",
+}
+
+// GroupInputs groups GqlInputs in a folder for every operation type, e.g. "Queries", when folders
+// is true. Otherwise, it returns a single folder without a name that contains all of them.
+func GroupInputs(gql []GqlInput, folders bool) []Folder {
+	if !folders {
+		return []Folder{{Inputs: gql}}
+	}
+
+	var groups []Folder
+	index := make(map[string]int)
+
+	for _, entry := range gql {
+		name, ok := folderNames[entry.OperationType]
+		if !ok {
+			name = "Other"
+		}
+
+		i, found := index[name]
+		if !found {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, Folder{Name: name})
+		}
+		groups[i].Inputs = append(groups[i].Inputs, entry)
+	}
+
+	return groups
+}
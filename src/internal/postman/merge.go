@@ -0,0 +1,319 @@
+package postman
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// RemovedPrefix is put in front of the name of a generated item whose operation no longer exists in the schema.
+const RemovedPrefix = "[REMOVED] "
+
+// GeneratedIDPrefix is put in front of the key of a GqlInput to get the id of its item. Merging only touches the
+// items that have an id with this prefix, every other item is left as is, even when it has a GraphQL body.
+const GeneratedIDPrefix = "graphql-postman:"
+
+// merger merges the items of a generated collection into the items of an existing collection.
+type merger struct {
+	generated map[string]Item // The generated items, by id
+	skipped   map[string]bool // The ids of the operations that are in the schema, but could not be generated
+	merged    map[string]bool // The ids of the generated items that are in the existing collection
+}
+
+// mergeItems updates the generated items in existing with the ones that are generated, and
+// flags the ones whose operation no longer exists. Folders are merged recursively.
+func (m *merger) mergeItems(existing []json.RawMessage) ([]json.RawMessage, error) {
+	merged := make([]json.RawMessage, len(existing))
+
+	for i, raw := range existing {
+		merged[i] = raw
+
+		var item object
+		if json.Unmarshal(raw, &item) != nil {
+			continue
+		}
+
+		// Folders
+		if _, ok := item.get("request"); !ok {
+			subRaw, ok := item.get("item")
+			if !ok {
+				continue
+			}
+			var sub []json.RawMessage
+			if json.Unmarshal(subRaw, &sub) != nil {
+				continue
+			}
+			sub, err := m.mergeItems(sub)
+			if err != nil {
+				return nil, err
+			}
+			item.set("item", marshalArray(sub))
+			if merged[i], err = item.MarshalJSON(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		id, legacyName := item.getString("id"), ""
+		if !strings.HasPrefix(id, GeneratedIDPrefix) {
+			// Items that were generated before they got an id are named after the field of their operation
+			key := legacyKey(item)
+			if _, ok := m.generated[GeneratedIDPrefix+key]; key == "" || !ok {
+				continue
+			}
+			id, legacyName = GeneratedIDPrefix+key, item.getString("name")
+			if err := setString(&item, "id", id); err != nil {
+				return nil, err
+			}
+		}
+
+		var err error
+		g, ok := m.generated[id]
+		if !ok && m.skipped[id] {
+			// The operation is still in the schema, so the item is kept as it is
+			continue
+		} else if !ok {
+			// The operation was removed from the schema, flag it
+			name := item.getString("name")
+			if !strings.HasPrefix(name, RemovedPrefix) {
+				if err = setString(&item, "name", RemovedPrefix+name); err != nil {
+					return nil, err
+				}
+			}
+		} else if err = updateItem(&item, g, legacyName); err != nil {
+			return nil, err
+		} else {
+			m.merged[id] = true
+		}
+
+		if merged[i], err = item.MarshalJSON(); err != nil {
+			return nil, err
+		}
+	}
+
+	return merged, nil
+}
+
+// updateItem updates the body of the request of an existing item with the query and variables. Everything else that
+// the user edited (scripts, headers, examples, auth, ...) is kept, including the name, which is only updated when it
+// is the generated one, or the legacy name of an item that was generated before items got an id. RemovedPrefix is
+// taken off the name, as the operation is back in the schema.
+func updateItem(item *object, generated Item, legacyName string) error {
+	name := strings.TrimPrefix(item.getString("name"), RemovedPrefix)
+	if name == generated.Name || legacyName != "" && name == legacyName {
+		name = generated.Name
+	}
+	if err := setString(item, "name", name); err != nil {
+		return err
+	}
+
+	requestRaw, _ := item.get("request")
+	var request object
+	if err := json.Unmarshal(requestRaw, &request); err != nil {
+		// A request can also be a plain string with the url, which has no body yet
+		var url string
+		if json.Unmarshal(requestRaw, &url) != nil {
+			return errors.New(`the request of "` + generated.Name + `" is neither an object nor a url`)
+		}
+		request = object{{Key: "url", Value: requestRaw}}
+	}
+
+	body, err := marshal(generated.Request.Body)
+	if err != nil {
+		return err
+	}
+	request.set("body", body)
+
+	requestRaw, err = request.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	item.set("request", requestRaw)
+
+	return nil
+}
+
+// legacyKey returns the key of the operation of an item that was generated before items got an id, or an empty
+// string when the item does not look like one. Those items are named after the root field that their query selects,
+// like "user" for "query user($id: ID!) { user(id: $id) { __typename } }", which is what this looks for.
+func legacyKey(item object) string {
+	var request struct {
+		Body struct {
+			Mode    string `json:"mode"`
+			Raw     string `json:"raw"`
+			GraphQL struct {
+				Query string `json:"query"`
+			} `json:"graphql"`
+		} `json:"body"`
+	}
+	raw, _ := item.get("request")
+	if json.Unmarshal(raw, &request) != nil {
+		return ""
+	}
+
+	query := request.Body.GraphQL.Query
+	if request.Body.Mode == ModeRaw {
+		var payload struct {
+			Query string `json:"query"`
+		}
+		if json.Unmarshal([]byte(request.Body.Raw), &payload) != nil {
+			return ""
+		}
+		query = payload.Query
+	}
+
+	// The operation type is the first word, the field is the first name in the selection set
+	selection := strings.Index(query, "{")
+	if selection < 0 {
+		return ""
+	}
+	words := strings.Fields(query[:selection])
+	if len(words) == 0 || words[0] != "query" && words[0] != "mutation" {
+		return ""
+	}
+	names := strings.FieldsFunc(query[selection+1:], func(r rune) bool {
+		return !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	if len(names) == 0 || names[0] != item.getString("name") {
+		return ""
+	}
+
+	return words[0] + "." + names[0]
+}
+
+// setString sets the value of a key to a string.
+func setString(o *object, key, value string) error {
+	raw, err := marshal(value)
+	if err != nil {
+		return err
+	}
+	o.set(key, raw)
+
+	return nil
+}
+
+// generatedItem is an item of a generated collection, with the name of the folder it is in.
+type generatedItem struct {
+	Item
+	Folder string // Empty when it is not in a folder
+}
+
+// flattenItems returns all of the items of a generated collection that are not folders.
+func flattenItems(items []Item, folder string) []generatedItem {
+	var flat []generatedItem
+	for _, item := range items {
+		if item.Request == nil {
+			flat = append(flat, flattenItems(item.Item, item.Name)...)
+		} else {
+			flat = append(flat, generatedItem{Item: item, Folder: folder})
+		}
+	}
+
+	return flat
+}
+
+// Merge merges a generated collection into an existing collection, which is given, and returned, as a json document.
+//
+// Only the items of the existing collection that were generated, which have an id that starts with GeneratedIDPrefix,
+// are touched, and the ones that were generated before items got an id, see legacyKey. The ones whose operation is
+// generated again get an updated name, and an updated body with the query and variables, while the rest of the item
+// (scripts, headers, examples) is kept as is. The ones whose operation is no longer in the schema have their name
+// prefixed with RemovedPrefix. The ones whose operation is skipped, which is in the schema but could not be generated,
+// are left as they are, skipped contains the keys of those, see GqlInput.Key. Generated items that are not in the
+// existing collection yet are added at the end, in a folder with the same name as in the generated collection if it
+// has one.
+//
+// Everything else in the existing collection, including what this package has no struct fields for, is written back
+// as is, with the same indentation.
+func Merge(existing []byte, generated Collection, skipped []string) ([]byte, error) {
+	var root object
+	if err := json.Unmarshal(existing, &root); err != nil {
+		return nil, errors.New("the collection is not a json object: " + err.Error())
+	}
+	var items []json.RawMessage
+	if raw, ok := root.get("item"); ok {
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, errors.New("the items of the collection are not a list: " + err.Error())
+		}
+	}
+
+	flat := flattenItems(generated.Item, "")
+	m := merger{generated: make(map[string]Item, len(flat)), skipped: make(map[string]bool), merged: make(map[string]bool)}
+	for _, item := range flat {
+		m.generated[item.ID] = item.Item
+	}
+	for _, key := range skipped {
+		m.skipped[GeneratedIDPrefix+key] = true
+	}
+
+	items, err := m.mergeItems(items)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the new operations, in the order in which they were generated
+	for _, item := range flat {
+		if m.merged[item.ID] {
+			continue
+		}
+		newItem, err := marshal(item.Item)
+		if err != nil {
+			return nil, err
+		}
+
+		if item.Folder == "" {
+			items = append(items, newItem)
+			continue
+		}
+
+		if items, err = addToFolder(items, item.Folder, newItem); err != nil {
+			return nil, err
+		}
+	}
+
+	root.set("item", marshalArray(items))
+	data, err := root.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	return layoutLike(data, existing)
+}
+
+// addToFolder adds an item to the folder with a name, the folder is added at the end when there is none with that name.
+func addToFolder(items []json.RawMessage, folder string, newItem json.RawMessage) ([]json.RawMessage, error) {
+	for i, raw := range items {
+		var item object
+		if json.Unmarshal(raw, &item) != nil || item.getString("name") != folder {
+			continue
+		}
+		if _, ok := item.get("request"); ok {
+			continue
+		}
+
+		var sub []json.RawMessage
+		if raw, ok := item.get("item"); ok {
+			if err := json.Unmarshal(raw, &sub); err != nil {
+				continue
+			}
+		}
+		item.set("item", marshalArray(append(sub, newItem)))
+
+		var err error
+		items[i], err = item.MarshalJSON()
+		return items, err
+	}
+
+	var item object
+	if err := setString(&item, "name", folder); err != nil {
+		return nil, err
+	}
+	item.set("item", marshalArray([]json.RawMessage{newItem}))
+
+	data, err := item.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(items, data), nil
+}
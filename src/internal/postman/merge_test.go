@@ -0,0 +1,199 @@
+package postman
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+)
+
+// exported is a collection as Postman exports it, with everything that the structs of this package do not know about.
+const exported = "testdata/exported.postman_collection.json"
+
+func TestObjectRoundTrip(t *testing.T) {
+	original, err := ioutil.ReadFile(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		original []byte
+	}{
+		{"tabs", original},
+		{"tabs and a newline", append(append([]byte{}, original...), '\n')},
+		{"spaces", indent(t, original, "    ")},
+		{"compact", compact(t, original)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var o object
+			if err := json.Unmarshal(tt.original, &o); err != nil {
+				t.Fatal(err)
+			}
+			data, err := o.MarshalJSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err = layoutLike(data, tt.original)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(data, tt.original) {
+				t.Errorf("the collection changed after decoding and encoding it:\n%s", data)
+			}
+		})
+	}
+}
+
+func TestMergeKeepsUnknownFields(t *testing.T) {
+	original, err := ioutil.ReadFile(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// None of the items are generated, so nothing changes
+	merged, err := Merge(original, Collection{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(merged, original) {
+		t.Errorf("merging nothing changed the collection:\n%s", merged)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	user := generated(t, GqlInput{Name: "QueryUser", Key: "query.user", OperationType: "query", Query: "query QueryUser { user { __typename } }"})
+	ship := generated(t, GqlInput{Name: "MutationCreateShip", Key: "mutation.createShip", OperationType: "mutation", Query: "mutation MutationCreateShip { createShip { __typename } }"})
+
+	for _, tt := range []struct {
+		name      string
+		existing  string
+		generated []Item
+		skipped   []string
+		want      string
+	}{
+		{
+			name:      "updates the body of a generated item, and keeps the rest",
+			existing:  `{"info":{},"item":[{"name":"QueryUser","id":"graphql-postman:query.user","event":[1],"request":{"method":"POST","header":[2],"body":{"mode":"raw","raw":"{}"},"url":"http://x","proxy":{}},"response":[3]}]}`,
+			generated: []Item{user},
+			want:      `{"info":{},"item":[{"name":"QueryUser","id":"graphql-postman:query.user","event":[1],"request":{"method":"POST","header":[2],"body":{"mode":"graphql","graphql":{"query":"query QueryUser { user { __typename } }","variables":""}},"url":"http://x","proxy":{}},"response":[3]}]}`,
+		},
+		{
+			name:      "keeps the name of a generated item that the user renamed",
+			existing:  `{"item":[{"name":"Get the user","id":"graphql-postman:query.user","request":{}}]}`,
+			generated: []Item{user},
+			want:      `{"item":[{"name":"Get the user","id":"graphql-postman:query.user","request":{"body":{"mode":"graphql","graphql":{"query":"query QueryUser { user { __typename } }","variables":""}}}}]}`,
+		},
+		{
+			name:      "takes the removed flag off the name when the operation is back",
+			existing:  `{"item":[{"name":"[REMOVED] Get the user","id":"graphql-postman:query.user","request":{}}]}`,
+			generated: []Item{user},
+			want:      `{"item":[{"name":"Get the user","id":"graphql-postman:query.user","request":{"body":{"mode":"graphql","graphql":{"query":"query QueryUser { user { __typename } }","variables":""}}}}]}`,
+		},
+		{
+			name:      "leaves hand-written GraphQL items alone, even with the same name",
+			existing:  `{"item":[{"name":"QueryUser","request":{"body":{"mode":"graphql","graphql":{"query":"{ user { id } }"}}}}]}`,
+			generated: []Item{user},
+			want: `{"item":[{"name":"QueryUser","request":{"body":{"mode":"graphql","graphql":{"query":"{ user { id } }"}}}},` +
+				string(marshalItem(t, user)) + `]}`,
+		},
+		{
+			name:      "adopts items that were generated before they got an id, by the field that they are named after",
+			existing:  `{"item":[{"name":"user","request":{"body":{"mode":"graphql","graphql":{"query":"query user($id: ID!) { user(id: $id) { __typename } }"}}}}]}`,
+			generated: []Item{user},
+			want:      `{"item":[{"name":"QueryUser","request":{"body":{"mode":"graphql","graphql":{"query":"query QueryUser { user { __typename } }","variables":""}}},"id":"graphql-postman:query.user"}]}`,
+		},
+		{
+			name:      "adopts raw items that were generated before they got an id",
+			existing:  `{"item":[{"name":"user","request":{"body":{"mode":"raw","raw":"{\"query\":\"query user { user { __typename } }\"}"}}}]}`,
+			generated: []Item{user},
+			want:      `{"item":[{"name":"QueryUser","request":{"body":{"mode":"graphql","graphql":{"query":"query QueryUser { user { __typename } }","variables":""}}},"id":"graphql-postman:query.user"}]}`,
+		},
+		{
+			name:      "does not adopt items without an id that are named after another field",
+			existing:  `{"item":[{"name":"me","request":{"body":{"mode":"graphql","graphql":{"query":"query me { user { id } }"}}}}]}`,
+			generated: []Item{user},
+			want: `{"item":[{"name":"me","request":{"body":{"mode":"graphql","graphql":{"query":"query me { user { id } }"}}}},` +
+				string(marshalItem(t, user)) + `]}`,
+		},
+		{
+			name:     "flags generated items whose operation was removed, once",
+			existing: `{"item":[{"name":"QueryUser","id":"graphql-postman:query.user","request":{}},{"name":"[REMOVED] QueryOld","id":"graphql-postman:query.old","request":{}}]}`,
+			want:     `{"item":[{"name":"[REMOVED] QueryUser","id":"graphql-postman:query.user","request":{}},{"name":"[REMOVED] QueryOld","id":"graphql-postman:query.old","request":{}}]}`,
+		},
+		{
+			name:     "keeps the items of operations that are skipped, which are still in the schema",
+			existing: `{"item":[{"name":"QueryUser","id":"graphql-postman:query.user","request":{}}]}`,
+			skipped:  []string{"query.user"},
+			want:     `{"item":[{"name":"QueryUser","id":"graphql-postman:query.user","request":{}}]}`,
+		},
+		{
+			name:      "merges into folders, and adds new items to the folder with the same name",
+			existing:  `{"item":[{"name":"Queries","auth":{"type":"noauth"},"item":[{"name":"[REMOVED] QueryUser","id":"graphql-postman:query.user","request":"http://x"}]}]}`,
+			generated: []Item{{Name: "Queries", Item: []Item{user}}, {Name: "Mutations", Item: []Item{ship}}},
+			want: `{"item":[{"name":"Queries","auth":{"type":"noauth"},"item":[{"name":"QueryUser","id":"graphql-postman:query.user","request":{"url":"http://x","body":{"mode":"graphql","graphql":{"query":"query QueryUser { user { __typename } }","variables":""}}}}]},` +
+				`{"name":"Mutations","item":[` + string(marshalItem(t, ship)) + `]}]}`,
+		},
+		{
+			name:      "adds the items to an existing folder",
+			existing:  `{"item":[{"name":"Mutations","item":[]}]}`,
+			generated: []Item{{Name: "Mutations", Item: []Item{ship}}},
+			want:      `{"item":[{"name":"Mutations","item":[` + string(marshalItem(t, ship)) + `]}]}`,
+		},
+		{
+			name:      "adds the item list when the collection has none",
+			existing:  `{"info":{"name":"empty"}}`,
+			generated: []Item{user},
+			want:      `{"info":{"name":"empty"},"item":[` + string(marshalItem(t, user)) + `]}`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := Merge([]byte(tt.existing), Collection{Item: tt.generated}, tt.skipped)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(merged) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", merged, tt.want)
+			}
+		})
+	}
+}
+
+// generated returns the item that is generated for a GqlInput.
+func generated(t *testing.T, input GqlInput) Item {
+	items, err := createItems([]GqlInput{input}, Config{BodyMode: ModeGraphql, Version: Version210})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return items[0]
+}
+
+func marshalItem(t *testing.T, item Item) json.RawMessage {
+	data, err := marshal(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func indent(t *testing.T, data []byte, indent string) []byte {
+	var buffer bytes.Buffer
+	if err := json.Indent(&buffer, data, "", indent); err != nil {
+		t.Fatal(err)
+	}
+
+	return buffer.Bytes()
+}
+
+func compact(t *testing.T, data []byte) []byte {
+	var buffer bytes.Buffer
+	if err := json.Compact(&buffer, data); err != nil {
+		t.Fatal(err)
+	}
+
+	return buffer.Bytes()
+}
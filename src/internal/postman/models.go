@@ -1,9 +1,18 @@
 package postman
 
+import (
+	"encoding/json"
+	"errors"
+	"github.com/RobinCPel/graphql-postman/src/internal/graphql/introspection/reformatted"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
 type Info struct {
 	PostManID string `json:"_postman_id"` // Configurable, by default: "00000000-0000-0000-0000-000000000000"
 	Name      string `json:"name"`        // Configurable, by default: "GraphQL Postman"
-	Schema    string `json:"schema"`      // Always "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+	Schema    string `json:"schema"`      // Configurable, by default: "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
 }
 
 type Graphql struct {
@@ -12,90 +21,332 @@ type Graphql struct {
 }
 
 type Body struct {
-	Mode    string  `json:"mode"` // Always "graphql"
-	GraphQL Graphql `json:"graphql"`
+	Mode    string      `json:"mode"`              // Configurable, by default: "graphql"
+	Raw     string      `json:"raw,omitempty"`     // Only in raw mode, the Payload of a GqlInput
+	GraphQL *Graphql    `json:"graphql,omitempty"` // Only in graphql mode
+	Options interface{} `json:"options,omitempty"` // Only in raw mode, and only in v2.1.0
 }
 
 type Url struct {
-	Raw      string   `json:"raw"`      // Always "http://localhost/gql"
-	Protocol string   `json:"protocol"` // Always "http"
-	Host     []string `json:"host"`     // Always "localhost"
-	Path     []string `json:"path"`     // Always ["gql"]
+	Raw      string   `json:"raw"`                // Configurable, by default: "http://localhost/gql"
+	Protocol string   `json:"protocol,omitempty"` // By default: "http"
+	Host     []string `json:"host,omitempty"`     // By default: ["localhost"]
+	Port     string   `json:"port,omitempty"`
+	Path     []string `json:"path,omitempty"` // By default: ["gql"]
+}
+
+type AuthAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type"` // Always "string"
+}
+
+type Auth struct {
+	Type   string      `json:"type"`   // Always "bearer"
+	Bearer interface{} `json:"bearer"` // A list of AuthAttribute's in v2.1.0, a map in v2.0.0
+}
+
+type Header struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type"` // Always "text"
+}
+
+// Response is a saved example response of a request.
+type Response struct {
+	Name            string   `json:"name"`
+	OriginalRequest *Request `json:"originalRequest,omitempty"`
+	Status          string   `json:"status"` // The status text, e.g. "OK"
+	Code            int      `json:"code"`   // The status code, e.g. 200
+	PreviewLanguage string   `json:"_postman_previewlanguage"`
+	Header          []Header `json:"header"`
+	Body            string   `json:"body"`
 }
 
 type Request struct {
 	Method string        `json:"method"` // Always "POST"
-	Header []interface{} `json:"header"` // Always empty
-	Body   Body          `json:"body"`
+	Header []interface{} `json:"header"` // The configured headers, the content type in raw mode, and the operation name header
+	Body   *Body         `json:"body,omitempty"`
 	URL    Url           `json:"url"`
 }
 
+// Item is either a request or a folder, in which case it only has a name and sub items.
 type Item struct {
-	Name     string        `json:"name"` // Name of the GQL query
-	Request  Request       `json:"request"`
-	Response []interface{} `json:"response"` // Always empty
+	Name     string        `json:"name"`         // Name of the GQL query
+	ID       string        `json:"id,omitempty"` // GeneratedIDPrefix followed by the key of the GqlInput, not set on folders
+	Item     []Item        `json:"item,omitempty"`
+	Request  *Request      `json:"request,omitempty"`
+	Response []interface{} `json:"response,omitempty"` // Only filled when the GqlInput has examples
 }
 
-// Collection represents a Postman Collection v2.1.
+// Collection represents a Postman Collection v2.1.0 or v2.0.0.
 type Collection struct {
-	Info Info   `json:"info"`
-	Item []Item `json:"item"`
+	Info Info        `json:"info"`
+	Item []Item      `json:"item"`
+	Auth interface{} `json:"auth,omitempty"`
 }
 
 // GqlInput examples:
 //
-// Name:      "CreateShip" => doesn't really matter tbh
-// Query:	  "mutation CreateShip($input: CreateShipInput!) { createShip(input: $input) { __typename }"
-// Variables: `{"input":{"name":"anything","speed":3}}`
+// Name:          "MutationCreateShip" => name of the item
+// OperationName: "MutationCreateShip" => unique for every GqlInput
+// Query:         "mutation MutationCreateShip($input: CreateShipInput!) { createShip(input: $input) { __typename } }"
+// Variables:     `{"input":{"name":"anything","speed":3}}`
 //
-// Name:      "Node"
-// Query:     "query Node($id: ID!) { node(id: $id) { __typename } }"
-// Variables: `{"id": "anything"}`
+// Name:          "QueryNode"
+// OperationName: "QueryNode"
+// Query:         "query QueryNode($id: ID!) { node(id: $id) { __typename } }"
+// Variables:     `{"id": "anything"}`
 type GqlInput struct {
-	Name      string
-	Query     string
-	Variables string
+	Name          string
+	Key           string // Identifies the operation across runs, e.g. "query.user", to merge it into an existing collection
+	OperationName string
+	OperationType string                // Either "query" or "mutation"
+	Operation     reformatted.Operation // The operation that the query was generated from
+	Query         string
+	Variables     string
+	Examples      []Example // Example responses, optional
+}
+
+// Example is an example response of a GqlInput, either synthesized from the schema or recorded from the server.
+type Example struct {
+	Name   string
+	Status int
+	Header []Header
+	Body   string
+}
+
+// Payload returns the JSON body of the request, as it is sent to a GraphQL endpoint.
+func (g GqlInput) Payload() (string, error) {
+	variables := g.Variables
+	if variables == "" {
+		variables = "{}"
+	}
+
+	data, err := json.Marshal(struct {
+		Query         string          `json:"query"`
+		Variables     json.RawMessage `json:"variables"`
+		OperationName string          `json:"operationName,omitempty"`
+	}{
+		Query:         g.Query,
+		Variables:     json.RawMessage(variables),
+		OperationName: g.OperationName,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// Body modes in which the GraphQL requests can be stored.
+const (
+	ModeGraphql = "graphql" // Uses the GraphQL body of Postman, only supported by v2.1.0
+	ModeRaw     = "raw"     // Uses a raw JSON body with the query and variables, supported by all tooling
+)
+
+// Schema versions of the Postman Collection format.
+const (
+	Version200 = "2.0.0"
+	Version210 = "2.1.0"
+)
+
+// Config contains everything that can be configured about a generated collection.
+type Config struct {
+	ID       string   // The Postman Collection ID
+	Name     string   // The Postman Collection name
+	URL      string   // The URL every request is sent to, may contain variables like "{{baseUrl}}"
+	Token    string   // Bearer token used for every request, may contain variables like "{{token}}", no auth when empty
+	Headers  []Header // Headers that every request is sent with
+	BodyMode string   // One of the body modes, e.g. ModeRaw
+	Version  string   // One of the schema versions, e.g. Version210
+	Folders  bool     // Whether to group the requests in a folder for every operation type, see GroupInputs
+
+	// OperationNameHeader is the name of a header that every request sends its operation name in, for servers
+	// that route on it. The graphql body mode has no operation name, so that is the only way it is sent there.
+	OperationNameHeader string
+}
+
+// createUrl splits a raw URL into the parts Postman expects.
+// Unlike net/url, it is fine with variables like "{{baseUrl}}" in the URL, only the host and port are split with net/url.
+func createUrl(raw string) Url {
+	u := Url{Raw: raw}
+
+	rest := raw
+	if i := strings.Index(rest, "://"); i >= 0 {
+		u.Protocol = rest[:i]
+		rest = rest[i+3:]
+	}
+	if i := strings.IndexAny(rest, "?#"); i >= 0 {
+		rest = rest[:i]
+	}
+
+	parts := strings.Split(rest, "/")
+	host := parts[0]
+	if parsed, err := url.Parse("//" + host); err == nil {
+		// Postman keeps the brackets of an IPv6 host, like "[::1]"
+		host = parsed.Hostname()
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		u.Port = parsed.Port()
+	} else if i := strings.LastIndex(host, ":"); i >= 0 && !strings.HasSuffix(host, "}}") {
+		// A variable, like "{{host}}:4000"
+		u.Port = host[i+1:]
+		host = host[:i]
+	}
+	if strings.Contains(host, "{{") || strings.HasPrefix(host, "[") {
+		u.Host = []string{host}
+	} else {
+		u.Host = strings.Split(host, ".")
+	}
+
+	for _, p := range parts[1:] {
+		if p != "" {
+			u.Path = append(u.Path, p)
+		}
+	}
+
+	return u
+}
+
+// createBody returns the body of a request in the given mode.
+func createBody(entry GqlInput, mode, version string) (*Body, error) {
+	if mode == ModeGraphql {
+		return &Body{
+			Mode: ModeGraphql,
+			GraphQL: &Graphql{
+				Query:     entry.Query,
+				Variables: entry.Variables,
+			},
+		}, nil
+	}
+
+	payload, err := entry.Payload()
+	if err != nil {
+		return nil, err
+	}
+
+	body := Body{Mode: ModeRaw, Raw: payload}
+	if version != Version200 {
+		body.Options = map[string]interface{}{"raw": map[string]string{"language": "json"}}
+	}
+
+	return &body, nil
 }
 
 // createItems takes GqlInput data and stuffs it into a Postman Collection item.
-func createItems(gql []GqlInput) []Item {
+func createItems(gql []GqlInput, config Config) ([]Item, error) {
 	items := make([]Item, len(gql), len(gql))
 
 	for i, entry := range gql {
+		body, err := createBody(entry, config.BodyMode, config.Version)
+		if err != nil {
+			return nil, errors.New(`could not create the body of "` + entry.Name + `": ` + err.Error())
+		}
+
+		header := []interface{}{}
+		if body.Mode == ModeRaw {
+			header = append(header, Header{Key: "Content-Type", Value: "application/json", Type: "text"})
+		}
+		for _, h := range config.Headers {
+			header = append(header, h)
+		}
+		if config.OperationNameHeader != "" && entry.OperationName != "" {
+			header = append(header, Header{Key: config.OperationNameHeader, Value: entry.OperationName, Type: "text"})
+		}
+
+		request := Request{
+			Method: "POST",
+			Header: header,
+			Body:   body,
+			URL:    createUrl(config.URL),
+		}
+
+		responses := make([]interface{}, len(entry.Examples))
+		for j, e := range entry.Examples {
+			originalRequest := request
+			responses[j] = Response{
+				Name:            e.Name,
+				OriginalRequest: &originalRequest,
+				Status:          http.StatusText(e.Status),
+				Code:            e.Status,
+				PreviewLanguage: "json",
+				Header:          e.Header,
+				Body:            e.Body,
+			}
+		}
+
+		key := entry.Key
+		if key == "" {
+			key = entry.Name
+		}
+
 		items[i] = Item{
-			Name: entry.Name,
-			Request: Request{
-				Method: "POST",
-				Header: []interface{}{},
-				Body: Body{
-					Mode: "graphql",
-					GraphQL: Graphql{
-						Query:     entry.Query,
-						Variables: entry.Variables,
-					},
-				},
-				URL: Url{
-					Raw:      "http://localhost/gql",
-					Protocol: "http",
-					Host:     []string{"localhost"},
-					Path:     []string{"gql"},
-				},
-			},
-			Response: []interface{}{},
+			Name:     entry.Name,
+			ID:       GeneratedIDPrefix + key,
+			Request:  &request,
+			Response: responses,
 		}
 	}
 
-	return items
+	return items, nil
 }
 
 // CreateCollection returns a collection with all of the default values already set.
-func CreateCollection(gql []GqlInput, postmanID, name string) Collection {
-	return Collection{
+//
+// The v2.0.0 schema has no GraphQL body mode, so its collections always use raw mode.
+func CreateCollection(gql []GqlInput, config Config) (*Collection, error) {
+	if config.Version == "" {
+		config.Version = Version210
+	}
+	if config.BodyMode == "" {
+		config.BodyMode = ModeGraphql
+	}
+	if config.Version == Version200 {
+		config.BodyMode = ModeRaw
+	}
+
+	if config.Version != Version200 && config.Version != Version210 {
+		return nil, errors.New(`collection schema version "` + config.Version + `" is not supported`)
+	}
+	if config.BodyMode != ModeGraphql && config.BodyMode != ModeRaw {
+		return nil, errors.New(`body mode "` + config.BodyMode + `" is not supported`)
+	}
+
+	var items []Item
+	for _, folder := range GroupInputs(gql, config.Folders) {
+		folderItems, err := createItems(folder.Inputs, config)
+		if err != nil {
+			return nil, err
+		}
+
+		if folder.Name == "" {
+			items = append(items, folderItems...)
+		} else {
+			items = append(items, Item{Name: folder.Name, Item: folderItems})
+		}
+	}
+
+	col := Collection{
 		Info: Info{
-			PostManID: postmanID,
-			Name:      name,
-			Schema:    "https://schema.getpostman.com/json/collection/v2.1.0/collection.json",
+			PostManID: config.ID,
+			Name:      config.Name,
+			Schema:    "https://schema.getpostman.com/json/collection/v" + config.Version + "/collection.json",
 		},
-		Item: createItems(gql),
+		Item: items,
 	}
+
+	if config.Token != "" {
+		auth := Auth{
+			Type:   "bearer",
+			Bearer: []AuthAttribute{{Key: "token", Value: config.Token, Type: "string"}},
+		}
+		if config.Version == Version200 {
+			auth.Bearer = map[string]string{"token": config.Token}
+		}
+		col.Auth = auth
+	}
+
+	return &col, nil
 }
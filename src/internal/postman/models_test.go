@@ -0,0 +1,27 @@
+package postman
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCreateUrl(t *testing.T) {
+	for _, tt := range []struct {
+		raw  string
+		want Url
+	}{
+		{"http://localhost/gql", Url{Protocol: "http", Host: []string{"localhost"}, Path: []string{"gql"}}},
+		{"https://api.example.com:8443/v1/gql?x=1", Url{Protocol: "https", Host: []string{"api", "example", "com"}, Port: "8443", Path: []string{"v1", "gql"}}},
+		{"http://[::1]:4000/gql", Url{Protocol: "http", Host: []string{"[::1]"}, Port: "4000", Path: []string{"gql"}}},
+		{"http://[2001:db8::1]/gql", Url{Protocol: "http", Host: []string{"[2001:db8::1]"}, Path: []string{"gql"}}},
+		{"{{baseUrl}}/gql", Url{Host: []string{"{{baseUrl}}"}, Path: []string{"gql"}}},
+		{"http://{{host}}:4000/gql", Url{Protocol: "http", Host: []string{"{{host}}"}, Port: "4000", Path: []string{"gql"}}},
+	} {
+		t.Run(tt.raw, func(t *testing.T) {
+			tt.want.Raw = tt.raw
+			if got := createUrl(tt.raw); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
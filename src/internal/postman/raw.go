@@ -0,0 +1,146 @@
+package postman
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// member is a key of a json object, with its value exactly as it is in the document.
+type member struct {
+	Key   string
+	Value json.RawMessage
+}
+
+// object is a json object that keeps the order of its keys, and the values as they are. An existing collection is
+// merged as objects, so that everything that the structs of this package do not know about is written back as is.
+type object []member
+
+func (o *object) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if t, err := decoder.Token(); err != nil || t != json.Delim('{') {
+		return errors.New("not a json object")
+	}
+
+	*o = nil
+	for decoder.More() {
+		t, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := t.(string)
+
+		var value json.RawMessage
+		if err = decoder.Decode(&value); err != nil {
+			return err
+		}
+		*o = append(*o, member{Key: key, Value: value})
+	}
+
+	_, err := decoder.Token()
+	return err
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		key, err := marshal(m.Key)
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(key)
+		buffer.WriteByte(':')
+		buffer.Write(m.Value)
+	}
+	buffer.WriteByte('}')
+
+	return buffer.Bytes(), nil
+}
+
+// get returns the value of a key, and whether the object has the key.
+func (o object) get(key string) (json.RawMessage, bool) {
+	for _, m := range o {
+		if m.Key == key {
+			return m.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// getString returns the value of a key when it is a string, and an empty string otherwise.
+func (o object) getString(key string) string {
+	var s string
+	if value, ok := o.get(key); ok {
+		_ = json.Unmarshal(value, &s)
+	}
+
+	return s
+}
+
+// set replaces the value of a key, or adds the key at the end when the object does not have it yet.
+func (o *object) set(key string, value json.RawMessage) {
+	for i, m := range *o {
+		if m.Key == key {
+			(*o)[i].Value = value
+			return
+		}
+	}
+
+	*o = append(*o, member{Key: key, Value: value})
+}
+
+// marshal encodes a value as json, without escaping "<", ">", and "&" like json.Marshal does,
+// since Postman does not escape those in the scripts and bodies that it exports either.
+func marshal(v interface{}) (json.RawMessage, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
+
+// marshalArray encodes json values as a json array.
+func marshalArray(values []json.RawMessage) json.RawMessage {
+	var buffer bytes.Buffer
+	buffer.WriteByte('[')
+	for i, v := range values {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		buffer.Write(v)
+	}
+	buffer.WriteByte(']')
+
+	return buffer.Bytes()
+}
+
+// layoutLike formats a json document the way that original is formatted, with the same
+// indentation, and with a newline at the end when original has one.
+func layoutLike(data, original []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	trimmed := bytes.TrimLeft(original, " \t\r\n")
+	if len(trimmed) > 1 && trimmed[0] == '{' && (trimmed[1] == '\n' || trimmed[1] == '\r') {
+		rest := bytes.TrimLeft(trimmed[1:], "\r\n")
+		indent := rest[:len(rest)-len(bytes.TrimLeft(rest, " \t"))]
+		if err := json.Indent(&buffer, data, "", string(indent)); err != nil {
+			return nil, err
+		}
+	} else if err := json.Compact(&buffer, data); err != nil {
+		return nil, err
+	}
+
+	if bytes.HasSuffix(original, []byte("\n")) {
+		buffer.WriteByte('\n')
+	}
+
+	return buffer.Bytes(), nil
+}
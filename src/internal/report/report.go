@@ -0,0 +1,86 @@
+package report
+
+import (
+	"github.com/RobinCPel/graphql-postman/src/internal/graphql/introspection/reformatted"
+	"sort"
+)
+
+// The statuses that an operation can have.
+const (
+	StatusGenerated = "generated" // The operation was generated without any warnings
+	StatusPartial   = "partial"   // The operation was generated, but parts of it are dummy values or missing
+	StatusSkipped   = "skipped"   // The operation could not be generated
+)
+
+// Warning is something that went wrong while generating an operation.
+type Warning struct {
+	Path    string `json:"path"` // Where it went wrong, e.g. "query.user(input).address"
+	Message string `json:"message"`
+}
+
+// Operation is the result of generating a single operation.
+type Operation struct {
+	Name          string    `json:"name"`          // The name of the field, e.g. "user"
+	OperationName string    `json:"operationName"` // The generated operation name, e.g. "QueryUser"
+	OperationType string    `json:"operationType"` // Either "query" or "mutation"
+	Status        string    `json:"status"`
+	Error         string    `json:"error,omitempty"` // Why the operation was skipped
+	Warnings      []Warning `json:"warnings,omitempty"`
+}
+
+// Totals counts everything that is in a report.
+type Totals struct {
+	Operations     int `json:"operations"`
+	Generated      int `json:"generated"`
+	Partial        int `json:"partial"`
+	Skipped        int `json:"skipped"`
+	Warnings       int `json:"warnings"`
+	UnknownScalars int `json:"unknownScalars"`
+	Truncations    int `json:"truncations"`
+}
+
+// Report describes how well a schema could be converted.
+type Report struct {
+	Operations     []Operation              `json:"operations"`
+	UnknownScalars []string                 `json:"unknownScalars"` // The scalars that got null as their dummy value
+	Truncations    []reformatted.Truncation `json:"truncations"`    // The type references in the schema that are incomplete
+	Totals         Totals                   `json:"totals"`
+}
+
+// CreateReport creates a report of the operations, and counts the totals.
+func CreateReport(operations []Operation, unknownScalars map[string]bool, truncations []reformatted.Truncation) Report {
+	r := Report{
+		Operations:     operations,
+		UnknownScalars: make([]string, 0, len(unknownScalars)),
+		Truncations:    truncations,
+	}
+	if r.Operations == nil {
+		r.Operations = make([]Operation, 0)
+	}
+	if r.Truncations == nil {
+		r.Truncations = make([]reformatted.Truncation, 0)
+	}
+
+	for name := range unknownScalars {
+		r.UnknownScalars = append(r.UnknownScalars, name)
+	}
+	sort.Strings(r.UnknownScalars)
+
+	for _, o := range r.Operations {
+		r.Totals.Operations++
+		r.Totals.Warnings += len(o.Warnings)
+
+		switch o.Status {
+		case StatusGenerated:
+			r.Totals.Generated++
+		case StatusPartial:
+			r.Totals.Partial++
+		case StatusSkipped:
+			r.Totals.Skipped++
+		}
+	}
+	r.Totals.UnknownScalars = len(r.UnknownScalars)
+	r.Totals.Truncations = len(r.Truncations)
+
+	return r
+}
@@ -0,0 +1,123 @@
+package script
+
+import (
+	"github.com/RobinCPel/graphql-postman/src/internal/postman"
+	"strings"
+)
+
+// Tools that the scripts can use to send the requests.
+const (
+	Curl   = "curl"
+	HTTPie = "httpie"
+)
+
+// Config contains everything that can be configured about the generated scripts.
+type Config struct {
+	Tool                string           // Either Curl or HTTPie
+	URL                 string           // The default URL of the GraphQL endpoint, when GRAPHQL_URL is not set
+	Token               string           // The default bearer token, when GRAPHQL_TOKEN is not set, optional
+	Headers             []postman.Header // Headers that every request is sent with
+	OperationNameHeader string           // The name of a header that every request sends its operation name in, optional
+}
+
+// quote quotes a string for a POSIX shell, it is put between single quotes, which keep everything as is.
+func quote(s string) string {
+	return `'` + strings.ReplaceAll(s, `'`, `'\''`) + `'`
+}
+
+// doubleQuoteEscaper escapes the characters that have a special meaning in a "${VAR:-default}" in a POSIX shell.
+var doubleQuoteEscaper = strings.NewReplacer(`\`, `\\`, `$`, `\$`, "`", "\\`", `"`, `\"`, `}`, `\}`)
+
+// preamble returns the shell script that sets up the environment variables that the invocations use.
+func preamble(config Config) string {
+	token := "optional"
+	if config.Token != "" {
+		token = "by default the one that the script was generated with"
+	}
+
+	p := `#!/bin/sh
+# Generated by graphql-postman, the requests can be configured with these environment variables:
+#
+# GRAPHQL_URL    The URL of the GraphQL endpoint, by default: ` + config.URL + `
+# GRAPHQL_TOKEN  The bearer token to send with every request, ` + token + `
+
+GRAPHQL_URL="${GRAPHQL_URL:-` + doubleQuoteEscaper.Replace(config.URL) + `}"
+`
+	if config.Token != "" {
+		p += `GRAPHQL_TOKEN="${GRAPHQL_TOKEN:-` + doubleQuoteEscaper.Replace(config.Token) + `}"
+`
+	}
+
+	return p
+}
+
+// invocation returns the commented invocation of the tool that sends a GqlInput.
+func invocation(entry postman.GqlInput, config Config) (string, error) {
+	payload, err := entry.Payload()
+	if err != nil {
+		return "", err
+	}
+
+	headers := config.Headers
+	if config.OperationNameHeader != "" && entry.OperationName != "" {
+		headers = append(headers[:len(headers):len(headers)], postman.Header{Key: config.OperationNameHeader, Value: entry.OperationName})
+	}
+
+	var lines []string
+	switch config.Tool {
+	case HTTPie:
+		lines = append(lines,
+			`http --ignore-stdin --raw `+quote(payload)+` POST "$GRAPHQL_URL"`,
+			`  Content-Type:application/json`,
+			`  ${GRAPHQL_TOKEN:+"Authorization:Bearer $GRAPHQL_TOKEN"}`,
+		)
+		for _, h := range headers {
+			lines = append(lines, `  `+quote(h.Key+":"+h.Value))
+		}
+
+	default:
+		lines = append(lines,
+			`curl -sS -X POST "$GRAPHQL_URL"`,
+			`  -H 'Content-Type: application/json'`,
+			`  ${GRAPHQL_TOKEN:+-H "Authorization: Bearer $GRAPHQL_TOKEN"}`,
+		)
+		for _, h := range headers {
+			lines = append(lines, `  -H `+quote(h.Key+": "+h.Value))
+		}
+		lines = append(lines, `  --data-raw `+quote(payload))
+	}
+
+	return "# " + entry.OperationName + ": " + entry.OperationType + " " + entry.Operation.Signature() + "\n" +
+		strings.Join(lines, " \\\n") + "\necho\n", nil
+}
+
+// CreateScript returns a single shell script that sends every GqlInput.
+func CreateScript(gql []postman.GqlInput, config Config) ([]byte, error) {
+	script := preamble(config)
+
+	for _, entry := range gql {
+		i, err := invocation(entry, config)
+		if err != nil {
+			return nil, err
+		}
+		script += "\n" + i
+	}
+
+	return []byte(script), nil
+}
+
+// CreateScripts returns a "<OperationName>.sh" shell script for every GqlInput.
+// The key of the returned map is the name of the file.
+func CreateScripts(gql []postman.GqlInput, config Config) (map[string][]byte, error) {
+	scripts := make(map[string][]byte, len(gql))
+
+	for _, entry := range gql {
+		i, err := invocation(entry, config)
+		if err != nil {
+			return nil, err
+		}
+		scripts[entry.Name+".sh"] = []byte(preamble(config) + "\n" + i)
+	}
+
+	return scripts, nil
+}
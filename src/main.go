@@ -1,28 +1,81 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"github.com/RobinCPel/graphql-postman/src/internal/export"
+	"github.com/RobinCPel/graphql-postman/src/internal/graphql/execution"
 	"github.com/RobinCPel/graphql-postman/src/internal/graphql/introspection"
 	"github.com/RobinCPel/graphql-postman/src/internal/graphql/introspection/reformatted"
 	"github.com/RobinCPel/graphql-postman/src/internal/graphql/kind"
 	"github.com/RobinCPel/graphql-postman/src/internal/graphql/scalar"
+	"github.com/RobinCPel/graphql-postman/src/internal/graphql/sdl"
+	"github.com/RobinCPel/graphql-postman/src/internal/httpclient"
 	"github.com/RobinCPel/graphql-postman/src/internal/postman"
+	"github.com/RobinCPel/graphql-postman/src/internal/report"
 	log "github.com/sirupsen/logrus"
 	"io/ioutil"
 	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 )
 
 var types map[string]reformatted.Type
 
+// The warnings of the operation that is being converted, and all unknown scalars, for the report.
+var warnings []report.Warning
+var unknownScalars = make(map[string]bool)
+
+// The input objects that a dummy value is being made of, to stop at input objects that contain themselves.
+var inputObjectsInProgress = make(map[string]bool)
+
+// stringSlice is a flag that can be passed multiple times.
+type stringSlice []string
+
+func (s *stringSlice) String() string {
+	return strings.Join(*s, ", ")
+}
+
+func (s *stringSlice) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
 func init() {
+	// Everything but the result goes to stderr, so that the result can be written to stdout
+	log.SetOutput(os.Stderr)
 	log.SetLevel(log.DebugLevel)
 }
 
-func getDummyValueOfScalar(scalarName string) string {
+// sortedKeys returns the keys of a map of type references in alphabetical order.
+func sortedKeys(m map[string]reformatted.TypeRef) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// warn logs a warning about the operation that is being converted, and adds it to the warnings for the report.
+// The path is where in the operation it went wrong, e.g. "query.user(input).address".
+func warn(path, message string) {
+	log.WithField("path", path).Warning(message)
+	warnings = append(warnings, report.Warning{Path: path, Message: message})
+}
+
+func getDummyValueOfScalar(scalarName, path string) string {
 	switch strings.ToLower(scalarName) {
 	case scalar.Integer:
 		return `4200`
@@ -36,34 +89,62 @@ func getDummyValueOfScalar(scalarName string) string {
 		return `"V2llRGl0TGVlc3RJc0dlaw=="`
 	}
 
-	log.Warning(`scalar "` + scalarName + `" does not exist, using NULL as dummy value`)
-	return `NULL`
+	unknownScalars[scalarName] = true
+	message := `scalar "` + scalarName + `" does not exist, using null as dummy value`
+	if t, ok := types[scalarName]; ok && t.SpecifiedByURL != "" {
+		message += `, it is specified by ` + t.SpecifiedByURL
+	}
+	warn(path, message)
+	return `null`
 }
 
-func getDummyValueOfType(typeName, typeKind string) (string, error) {
+// getDummyValueOfType returns a dummy value of a type, the path is where in the operation the value is,
+// e.g. "query.user(input).address", which every error starts with.
+func getDummyValueOfType(typeName, typeKind, path string) (string, error) {
 	t, ok := types[typeName]
 	if !ok {
-		return "", errors.New(`could not find the type "` + typeName + `" in the types map`)
+		return "", errors.New(path + `: could not find the type "` + typeName + `" in the types map`)
 	}
 
 	emptyResponse := func() (string, error) {
-		return "NULL", nil
+		return "null", nil
 	}
 
 	switch strings.ToLower(typeKind) {
 
 	case kind.Scalar: // Has only a name
-		return getDummyValueOfScalar(t.Name), nil
+		return getDummyValueOfScalar(t.Name, path), nil
 
 	case kind.InputObject: // Has only a name and input fields
+		if inputObjectsInProgress[t.Name] {
+			warn(path, `input object "`+t.Name+`" contains itself, using null as dummy value`)
+			return emptyResponse()
+		}
+		inputObjectsInProgress[t.Name] = true
+		defer delete(inputObjectsInProgress, t.Name)
+
+		// Exactly one field of a @oneOf input object has to be set, so only the first one is
+		keys := sortedKeys(t.InputFields)
+		if t.OneOf && len(keys) > 1 {
+			keys = keys[:1]
+		}
+
 		dummyValue := `{`
 		var count int
-		for key, val := range t.InputFields {
+		for _, key := range keys {
+			val := t.InputFields[key]
 			count++
 
-			typeDummyVal, err := getDummyValueOfType(val.Name, val.Kind)
+			if val.Truncated != "" {
+				warn(path+"."+key, val.Truncated)
+			}
+			if val.Name == "" {
+				return "", errors.New(path + "." + key + `: the type of the input field has no name`)
+			}
+
+			typeDummyVal, err := getDummyValueOfType(val.Name, val.Kind, path+"."+key)
 			if err != nil {
-				log.Fatal(`Could not get the dummy value of type with name "` + val.Name + `"`)
+				return "", err
 			}
 
 			if val.List {
@@ -76,7 +157,7 @@ func getDummyValueOfType(typeName, typeKind string) (string, error) {
 				dummyValue += `"` + key + `":` + typeDummyVal
 			}
 
-			if count != len(t.InputFields) {
+			if count != len(keys) {
 				dummyValue += `,`
 			}
 		}
@@ -88,43 +169,89 @@ func getDummyValueOfType(typeName, typeKind string) (string, error) {
 		if len(t.EnumValues) > 0 {
 			return `"` + t.EnumValues[rand.Intn(len(t.EnumValues))] + `"`, nil
 		} else {
-			log.WithField("name", t.Name).Warning("Found an enum without values")
+			warn(path, `enum "`+t.Name+`" has no values, using null as dummy value`)
 			return emptyResponse()
 		}
 
 	case kind.Interface: // Has only a name, fields, and possible types
-		log.Warning("Interfaces are only present in mutation and query responses, so a dummy value for an interface is not needed")
+		warn(path, "Interfaces are only present in mutation and query responses, so a dummy value for an interface is not needed")
 		return emptyResponse()
 
 	case kind.Object: // Has only a name and fields
-		log.Warning("Objects are only present in mutation and query responses, so a dummy value for an object is not needed")
+		warn(path, "Objects are only present in mutation and query responses, so a dummy value for an object is not needed")
 		return emptyResponse()
 
 	case kind.Union: // Has only a name and possible types
-		log.Warning("Unions are only present in mutation and query responses, so a dummy value for a union is not needed")
+		warn(path, "Unions are only present in mutation and query responses, so a dummy value for a union is not needed")
 		return emptyResponse()
 
-	default:
-		log.Fatal(`type of kind "` + typeKind + `" does not exist`)
 	}
 
-	return "", errors.New(`type of kind "` + typeKind + `" does not exist`)
+	return "", errors.New(path + `: type of kind "` + typeKind + `" does not exist`)
+}
+
+// operationNameOf returns the PascalCase name of an operation, prefixed with the
+// operation type, e.g. "QueryUser" for the query "user".
+func operationNameOf(operationType, fieldName string) string {
+	name := strings.ToUpper(operationType[:1]) + operationType[1:]
+
+	// Split snake_case and other non-alphanumeric names into words
+	words := strings.FieldsFunc(fieldName, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		name += strings.ToUpper(w[:1]) + w[1:]
+	}
+
+	return name
 }
 
-func gqlInputFromOperation(o reformatted.Operation, operationName string) (*postman.GqlInput, error) {
-	input := postman.GqlInput{Name: o.Name}
+// uniqueOperationName returns the operation name of an operation, with a number appended to it
+// when the name is already used. The returned name is added to used.
+func uniqueOperationName(operationType, fieldName string, used map[string]bool) string {
+	name := operationNameOf(operationType, fieldName)
+
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	used[unique] = true
+
+	return unique
+}
+
+// operationKey returns the key of a GqlInput of an operation, e.g. "query.user".
+func operationKey(operationType, fieldName string) string {
+	return operationType + "." + fieldName
+}
+
+// entityKey returns the key of a GqlInput that fetches an entity by one of its keys, e.g. "query._entities(Product: upc)".
+func entityKey(typeName, key string) string {
+	return "query._entities(" + typeName + ": " + strings.Join(strings.Fields(key), " ") + ")"
+}
+
+// gqlInputFromOperation converts an operation to a GqlInput, the operationType is either "query" or "mutation".
+func gqlInputFromOperation(o reformatted.Operation, operationType, operationName string) (*postman.GqlInput, error) {
+	input := postman.GqlInput{
+		Name:          operationName,
+		Key:           operationKey(operationType, o.Name),
+		OperationName: operationName,
+		OperationType: operationType,
+		Operation:     o,
+	}
+
+	if o.Type.Truncated != "" {
+		warn(operationType+"."+o.Name, o.Type.Truncated)
+	}
 
 	// Assemble the query
 	var count int
 	var argLine1, argLine2 string
-	for k, v := range o.Arguments {
+	for _, k := range sortedKeys(o.Arguments) {
+		v := o.Arguments[k]
 		count++
 
-		argLine1 += `$` + k + `: ` + v.Name
-		if v.NonNull {
-			argLine1 += `!`
-		}
-
+		argLine1 += `$` + k + `: ` + v.String()
 		argLine2 += k + `: $` + k
 
 		// Not the last one? Add the delimiter
@@ -133,39 +260,417 @@ func gqlInputFromOperation(o reformatted.Operation, operationName string) (*post
 			argLine2 += `, `
 		}
 	}
-	input.Query = operationName + ` ` + o.Name + `(` + argLine1 + `) { ` + o.Name + `(` + argLine2 + `) { __typename } }`
+	if argLine1 != "" {
+		argLine1 = `(` + argLine1 + `)`
+		argLine2 = `(` + argLine2 + `)`
+	}
 
-	// Assemble the dummy variables
-	count = 0
-	for k, v := range o.Arguments {
-		count++
+	// Scalars and enums can not have a selection set
+	selection := ` { __typename }`
+	if k := strings.ToLower(o.Type.Kind); k == kind.Scalar || k == kind.Enum {
+		selection = ``
+	}
+	input.Query = operationType + ` ` + operationName + argLine1 + ` { ` + o.Name + argLine2 + selection + ` }`
 
-		if v.List {
-			log.Warn("list graphql operation arguments are not supported")
+	// Assemble the dummy variables, sorted so that the result is the same for every run
+	variables := make([]string, 0, len(o.Arguments))
+	for _, k := range sortedKeys(o.Arguments) {
+		v := o.Arguments[k]
+		path := operationType + "." + o.Name + "(" + k + ")"
+		if v.Truncated != "" {
+			warn(path, v.Truncated)
+		}
+		if v.Name == "" {
+			return nil, errors.New(path + `: the type of the argument has no name`)
 		}
 
-		input.Variables += `{"` + k + `":`
-
-		dummyVal, err := getDummyValueOfType(v.Name, v.Kind)
+		dummyVal, err := getDummyValueOfType(v.Name, v.Kind, path)
 		if err != nil {
 			return nil, err
 		}
-		input.Variables += dummyVal
 
-		if count == len(o.Arguments) {
-			// Last one, close off the json
-			input.Variables += `}`
+		if v.TwoDList {
+			dummyVal = `[[` + dummyVal + `]]`
+		} else if v.List {
+			dummyVal = `[` + dummyVal + `]`
+		}
+		variables = append(variables, `"`+k+`":`+dummyVal)
+	}
+	input.Variables = `{` + strings.Join(variables, `, `) + `}`
+
+	return &input, nil
+}
+
+// entitiesOperation returns the _entities query of a federation subgraph, as it fetches the entities of a type.
+// The inline fragment on the type makes the result a list of that type, instead of a list of the _Entity union.
+func entitiesOperation(typeName string) reformatted.Operation {
+	return reformatted.Operation{
+		Name: "_entities",
+		Arguments: map[string]reformatted.TypeRef{
+			"representations": {Kind: kind.Scalar, Name: "_Any", NonNull: true, List: true, ListNonNull: true},
+		},
+		Type: reformatted.TypeRef{Kind: kind.Object, Name: typeName, List: true, ListNonNull: true},
+	}
+}
+
+// keyFieldsOf returns the dummy values of the fields of a @key, as "name":value pairs, the path is used in warnings.
+func keyFieldsOf(typeName string, selections []sdl.Selection, path string) ([]string, error) {
+	t, ok := types[typeName]
+	if !ok {
+		return nil, errors.New(`could not find the type "` + typeName + `" in the types map`)
+	}
+
+	fields := make([]string, 0, len(selections))
+	for _, s := range selections {
+		field, ok := t.Fields[s.Name]
+		if !ok {
+			return nil, errors.New(`the key field "` + path + "." + s.Name + `" does not exist`)
+		}
+
+		var value string
+		if len(s.Selections) > 0 {
+			nested, err := keyFieldsOf(field.Name, s.Selections, path+"."+s.Name)
+			if err != nil {
+				return nil, err
+			}
+			value = `{` + strings.Join(nested, `,`) + `}`
 		} else {
-			// Not the last one? Add the delimiter
-			input.Variables += `, `
+			var err error
+			if value, err = getDummyValueOfType(field.Name, field.Kind, path+"."+s.Name); err != nil {
+				return nil, err
+			}
+		}
+
+		if field.TwoDList {
+			value = `[[` + value + `]]`
+		} else if field.List {
+			value = `[` + value + `]`
 		}
+		fields = append(fields, `"`+s.Name+`":`+value)
 	}
 
+	return fields, nil
+}
+
+// entityFieldsOf returns the selections of the fields that a federation subgraph resolves of an entity, and their
+// dummy values, as "name":value pairs, in the shape of a response to those. Fields that return an object, an
+// interface, or a union only select their __typename, like gqlInputFromOperation does.
+func entityFieldsOf(e sdl.Entity, path string) ([]string, []string, error) {
+	t, ok := types[e.Name]
+	if !ok {
+		return nil, nil, errors.New(`could not find the type "` + e.Name + `" in the types map`)
+	}
+
+	selections := []string{"__typename"}
+	values := []string{`"__typename":"` + e.Name + `"`}
+	for _, name := range e.Fields {
+		field, ok := t.Fields[name]
+		if !ok {
+			continue
+		}
+
+		selection := name
+		if k := strings.ToLower(field.Kind); k != kind.Scalar && k != kind.Enum {
+			selection += ` { __typename }`
+		}
+		value, err := getDummyResponseOfType(field, path+"."+name)
+		if err != nil {
+			return nil, nil, err
+		}
+		selections = append(selections, selection)
+		values = append(values, `"`+name+`":`+value)
+	}
+
+	return selections, values, nil
+}
+
+// gqlInputFromEntity creates a GqlInput that fetches an entity of a federation subgraph by one of its keys,
+// with a representation of the entity that has a dummy value for every field of the key. The fields that the
+// subgraph resolves are selected.
+func gqlInputFromEntity(e sdl.Entity, key, operationName string) (*postman.GqlInput, error) {
+	typeName := e.Name
+	o := entitiesOperation(typeName)
+	input := postman.GqlInput{
+		Name:          operationName,
+		Key:           entityKey(typeName, key),
+		OperationName: operationName,
+		OperationType: "query",
+		Operation:     o,
+	}
+
+	selections, err := sdl.ParseFieldSet(key)
+	if err != nil {
+		return nil, errors.New(`the key "` + key + `" of "` + typeName + `" can not be parsed: ` + err.Error())
+	}
+	fields, err := keyFieldsOf(typeName, selections, "query."+o.Name+"("+typeName+")")
+	if err != nil {
+		return nil, err
+	}
+	selected, _, err := entityFieldsOf(e, "query."+o.Name+"("+typeName+")")
+	if err != nil {
+		return nil, err
+	}
+
+	input.Query = `query ` + operationName + `($representations: [_Any!]!) { ` + o.Name +
+		`(representations: $representations) { ... on ` + typeName + ` { ` + strings.Join(selected, ` `) + ` } } }`
+	input.Variables = `{"representations":[{"__typename":"` + typeName + `",` + strings.Join(fields, `,`) + `}]}`
+
 	return &input, nil
 }
 
+// getDummyResponseOfType returns a dummy value of a type, in the shape that it has in a
+// response to the selection set of gqlInputFromOperation.
+func getDummyResponseOfType(typeRef reformatted.TypeRef, path string) (string, error) {
+	var dummyValue string
+
+	switch strings.ToLower(typeRef.Kind) {
+
+	case kind.Scalar:
+		dummyValue = getDummyValueOfScalar(typeRef.Name, path)
+
+	case kind.Enum:
+		t, ok := types[typeRef.Name]
+		if !ok || len(t.EnumValues) == 0 {
+			warn(path, `enum "`+typeRef.Name+`" has no values, using null as dummy value`)
+			dummyValue = `null`
+		} else {
+			dummyValue = `"` + t.EnumValues[0] + `"`
+		}
+
+	case kind.Object:
+		dummyValue = `{"__typename":"` + typeRef.Name + `"}`
+
+	case kind.Interface, kind.Union: // Use the first type that it can be
+		name := typeRef.Name
+		if t, ok := types[typeRef.Name]; ok && len(t.PossibleTypes) > 0 {
+			name = t.PossibleTypes[0].Name
+		}
+		dummyValue = `{"__typename":"` + name + `"}`
+
+	default:
+		return "", errors.New(`type of kind "` + typeRef.Kind + `" can not be in a response`)
+	}
+
+	if typeRef.TwoDList {
+		return `[[` + dummyValue + `]]`, nil
+	}
+	if typeRef.List {
+		return `[` + dummyValue + `]`, nil
+	}
+	return dummyValue, nil
+}
+
+// exampleFromOperation synthesizes an example response of an operation, based on the schema.
+func exampleFromOperation(o reformatted.Operation, operationType string) (*postman.Example, error) {
+	dummyValue, err := getDummyResponseOfType(o.Type, operationType+"."+o.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &postman.Example{
+		Name:   "Example",
+		Status: http.StatusOK,
+		Header: []postman.Header{{Key: "Content-Type", Value: "application/json", Type: "text"}},
+		Body:   `{"data":{"` + o.Name + `":` + dummyValue + `}}`,
+	}, nil
+}
+
+// exampleFromEntity synthesizes an example response of the _entities query of gqlInputFromEntity.
+func exampleFromEntity(e sdl.Entity) (*postman.Example, error) {
+	_, values, err := entityFieldsOf(e, "query._entities("+e.Name+")")
+	if err != nil {
+		return nil, err
+	}
+
+	return &postman.Example{
+		Name:   "Example",
+		Status: http.StatusOK,
+		Header: []postman.Header{{Key: "Content-Type", Value: "application/json", Type: "text"}},
+		Body:   `{"data":{"_entities":[{` + strings.Join(values, `,`) + `}]}}`,
+	}, nil
+}
+
+// exampleFromExecution sends a GqlInput to the endpoint, and records the response as an example.
+// The request may take at most the timeout, unless it is zero.
+func exampleFromExecution(client *http.Client, input postman.GqlInput, url string, requestHeader http.Header, timeout time.Duration) (*postman.Example, error) {
+	payload, err := input.Payload()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	r, err := execution.Execute(ctx, client, url, payload, requestHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	headerKeys := make([]string, 0, len(r.Header))
+	for k := range r.Header {
+		headerKeys = append(headerKeys, k)
+	}
+	sort.Strings(headerKeys)
+
+	header := make([]postman.Header, 0, len(r.Header))
+	for _, k := range headerKeys {
+		for _, v := range r.Header[k] {
+			header = append(header, postman.Header{Key: k, Value: v, Type: "text"})
+		}
+	}
+
+	return &postman.Example{
+		Name:   "Response",
+		Status: r.Status,
+		Header: header,
+		Body:   string(r.Body),
+	}, nil
+}
+
+// parseHeader parses a header in the "Key: Value" notation.
+func parseHeader(header string) (postman.Header, error) {
+	kv := strings.SplitN(header, ":", 2)
+	if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+		return postman.Header{}, errors.New(`header "` + header + `" is not in the "Key: Value" notation`)
+	}
+
+	return postman.Header{Key: strings.TrimSpace(kv[0]), Value: strings.TrimSpace(kv[1]), Type: "text"}, nil
+}
+
+// parseVariable parses a variable in the "key=value" notation.
+func parseVariable(variable string) (postman.Variable, error) {
+	kv := strings.SplitN(variable, "=", 2)
+	if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+		return postman.Variable{}, errors.New(`variable "` + variable + `" is not in the "key=value" notation`)
+	}
+
+	return postman.Variable{Key: strings.TrimSpace(kv[0]), Value: kv[1]}, nil
+}
+
+// readVariables reads a file with a "key=value" variable on every line.
+// Empty lines and lines starting with a "#" are skipped.
+func readVariables(fileName string) ([]postman.Variable, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var variables []postman.Variable
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		v, err := parseVariable(line)
+		if err != nil {
+			return nil, err
+		}
+		v.Value = strings.TrimSpace(v.Value)
+		variables = append(variables, v)
+	}
+
+	return variables, nil
+}
+
+// readWordlist reads a file with a name on every line.
+// Empty lines and lines starting with a "#" are skipped.
+func readWordlist(fileName string) ([]string, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var words []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+
+	return words, nil
+}
+
+// formatOutput is a format, with the file or directory that its result is written to.
+type formatOutput struct {
+	Format string `json:"format"`
+	Output string `json:"output"` // Empty for the default output of the format
+}
+
+// parseFormat parses a format in the "name[=output]" notation.
+func parseFormat(format string) (formatOutput, error) {
+	kv := strings.SplitN(format, "=", 2)
+	if strings.TrimSpace(kv[0]) == "" {
+		return formatOutput{}, errors.New(`format "` + format + `" is not in the "name[=output]" notation`)
+	}
+
+	f := formatOutput{Format: strings.TrimSpace(kv[0])}
+	if len(kv) == 2 {
+		f.Output = strings.TrimSpace(kv[1])
+	}
+	return f, nil
+}
+
+// checkOutputs checks that no two results are written to the same output, and that at most one goes to stdout.
+// The outputs are the ones of the formats, and the other files that are written, by what is written to them.
+func checkOutputs(formats []formatOutput, files map[string]string) error {
+	outputs := make(map[string]string)
+	add := func(output, what string) error {
+		if output != export.Stdout {
+			output = filepath.Clean(output)
+		}
+		if other, ok := outputs[output]; ok {
+			if output == export.Stdout {
+				return errors.New(`both ` + other + ` and ` + what + ` are written to stdout, only one result can be`)
+			}
+			return errors.New(`both ` + other + ` and ` + what + ` are written to "` + output + `"`)
+		}
+		outputs[output] = what
+		return nil
+	}
+
+	for _, f := range formats {
+		if err := add(f.Output, `the "`+f.Format+`" format`); err != nil {
+			return err
+		}
+	}
+	for what, output := range files {
+		if output == "" {
+			continue
+		}
+		if err := add(output, what); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readFormats reads the formats from a json config file, like {"formats": [{"format": "har", "output": "api.har"}]}.
+func readFormats(fileName string) ([]formatOutput, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var config struct {
+		Formats []formatOutput `json:"formats"`
+	}
+	if err = json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	return config.Formats, nil
+}
+
 func main() {
-	fmt.Print(`
+	fmt.Fprint(os.Stderr, `
                                        (     
                                        (        (                                       
  (                            )   (    )\ )     )\ )             )                      
@@ -180,23 +685,286 @@ func main() {
 `)
 
 	// Define flags
-	var url, outputFileName, postmanCollectionID, postmanCollectionName string
+	var url, introspectionFileName, introspectionTransport, cacheDir, wordlistFileName, caFile, certFile, keyFile, serverName, proxy, outputFileName, configFileName, reportFileName, postmanCollectionID, postmanCollectionName, mergeFileName string
+	var targetURL, token, environmentFileName, environmentID, variablesFileName, bodyMode, schemaVersion string
+	var formatFlags, variables, headerFlags stringSlice
+	var operationNameHeader string
+	var examples, live, liveMutations, folders, scriptPerOperation, allowPartial, insecureSkipVerify, refresh, recoverSchema, federationSubgraph bool
+	var k6VUs, retries, typeDepth, recoverMaxRequests int
+	var recoverRate float64
+	var timeout, retryDelay, waitUntilUp, cacheTTL time.Duration
+	var k6Duration string
 	flag.StringVar(&url, "endpoint", "", "graphql endpoint to connect to")
-	flag.StringVar(&outputFileName, "output", "api.postman_collection.json", "the file to write the result to")
+	flag.StringVar(&caFile, "ca-file", "", "a PEM bundle with CA certificates to trust, next to the system ones, when connecting to the endpoint")
+	flag.StringVar(&certFile, "cert-file", "", "a PEM client certificate to connect to the endpoint with, requires -key-file")
+	flag.StringVar(&keyFile, "key-file", "", "the PEM private key of the client certificate")
+	flag.StringVar(&serverName, "server-name", "", "the name to verify the certificate of the endpoint with, instead of its host")
+	flag.BoolVar(&insecureSkipVerify, "insecure-skip-verify", false, "do not verify the certificate of the endpoint at all, only for testing")
+	flag.StringVar(&proxy, "proxy", "", "the url of an http, https, or socks5 proxy to connect to the endpoint through, instead of the one in HTTP_PROXY or HTTPS_PROXY")
+	flag.StringVar(&introspectionTransport, "introspection-transport", introspection.TransportPost, "how the introspection query is sent, either \"post\" (application/json), \"get\" (url parameters), or \"graphql\" (application/graphql), it falls back to another one on a 405 or 415 status")
+	flag.IntVar(&typeDepth, "type-depth", introspection.DefaultTypeRefDepth, "the number of levels of type references the introspection query asks for, the truncated ones are introspected again")
+	flag.StringVar(&cacheDir, "cache-dir", "", "a directory to cache introspection results in, per endpoint and headers")
+	flag.DurationVar(&cacheTTL, "cache-ttl", time.Hour, "how long a cached introspection result is used without asking the endpoint, after that it is revalidated with its ETag")
+	flag.BoolVar(&refresh, "refresh", false, "with -cache-dir, introspect the endpoint again, and replace the cached result")
+	flag.BoolVar(&recoverSchema, "recover", false, "when introspection is disabled, recover a part of the schema from the suggestions in the validation errors of the endpoint, only for authorized testing")
+	flag.StringVar(&wordlistFileName, "wordlist", "", "with -recover, a file with a name to try as a field, argument, input field, or enum value on every line, instead of the built-in ones")
+	flag.IntVar(&recoverMaxRequests, "recover-max-requests", 500, "with -recover, the most requests that are sent to the endpoint, 0 for no limit")
+	flag.Float64Var(&recoverRate, "recover-rate", 5, "with -recover, the most requests per second that are sent to the endpoint, 0 for no limit")
+	flag.DurationVar(&timeout, "timeout", 30*time.Second, "how long the introspection query, and every request of -live, may take, 0 for no limit")
+	flag.IntVar(&retries, "retries", 2, "how many times the introspection query is retried on connection errors, and on 5xx and 429 responses")
+	flag.DurationVar(&retryDelay, "retry-delay", time.Second, "how long to wait before the first retry, it doubles for every next retry")
+	flag.DurationVar(&waitUntilUp, "wait", 0, "keep retrying the introspection query for this long, for endpoints that are still starting up, e.g. \"5m\"")
+	flag.BoolVar(&federationSubgraph, "federation-subgraph", false, "fetch the schema of an Apollo Federation subgraph with _service { sdl } instead of introspecting it, and add an _entities query for every @key")
+	flag.StringVar(&introspectionFileName, "introspection-file", "", "a file with an introspection result to use instead of introspecting the endpoint, \"-\" reads it from stdin")
+	flag.Var(&formatFlags, "format", "the format of the result, optionally with the output as \"name=output\", can be used multiple times, either \""+strings.Join(export.Names(), "\", \"")+"\" (default \"postman\")")
+	flag.BoolVar(&allowPartial, "allow-partial", false, "continue with the schema when the introspection result has errors as well, instead of stopping")
+	flag.StringVar(&outputFileName, "output", "", "the file, or directory for graphql-files, bruno, and -script-per-operation, to write the result to when there is a single format (default depends on the format)")
+	flag.StringVar(&configFileName, "config", "", "a json file with a list of formats to write, like {\"formats\": [{\"format\": \"har\", \"output\": \"api.har\"}]}")
+	flag.StringVar(&reportFileName, "report", "", "the file to write a json report to, with the status of every operation, and the warnings")
 	flag.StringVar(&postmanCollectionID, "id", "00000000-0000-0000-0000-000000000000", "the Postman Collection ID to use")
 	flag.StringVar(&postmanCollectionName, "name", "GraphQL Postman", "the Postman Collection name to use")
+	flag.StringVar(&mergeFileName, "merge", "", "an existing Postman Collection v2.1 to merge the result into")
+	flag.StringVar(&targetURL, "target-url", "http://localhost/gql", "the url the requests in the result are sent to")
+	flag.StringVar(&token, "token", "", "the bearer token the requests in the result are sent with")
+	flag.Var(&headerFlags, "header", "a \"Key: Value\" header the requests in the result are sent with, can be used multiple times")
+	flag.BoolVar(&examples, "examples", false, "add a synthesized example response to every request")
+	flag.BoolVar(&live, "live", false, "send every query to the endpoint, and add the actual response as an example")
+	flag.BoolVar(&liveMutations, "live-mutations", false, "with -live, also send every mutation to the endpoint, which can change data")
+	flag.BoolVar(&scriptPerOperation, "script-per-operation", false, "write a script for every operation to a directory, instead of a single script, for the curl and httpie formats")
+	flag.IntVar(&k6VUs, "k6-vus", 1, "the number of virtual users of the k6 script")
+	flag.StringVar(&k6Duration, "k6-duration", "30s", "how long the k6 script runs")
+	flag.BoolVar(&folders, "folders", false, "group the requests in a \"Queries\" and a \"Mutations\" folder")
+	flag.StringVar(&operationNameHeader, "operation-name-header", "", "the name of a header to send the operation name of every request in, e.g. \"X-GraphQL-Operation-Name\"")
+	flag.StringVar(&bodyMode, "body-mode", postman.ModeGraphql, "the body mode of the requests, either \"graphql\" or \"raw\"")
+	flag.StringVar(&schemaVersion, "schema-version", postman.Version210, "the Postman Collection schema version, either \"2.1.0\" or \"2.0.0\" (which always uses the raw body mode)")
+	flag.StringVar(&environmentFileName, "environment", "", "the file to write a matching Postman Environment to")
+	flag.StringVar(&environmentID, "environment-id", "00000000-0000-0000-0000-000000000000", "the Postman Environment ID to use")
+	flag.Var(&variables, "var", "an extra \"key=value\" variable for the Postman Environment, can be used multiple times")
+	flag.StringVar(&variablesFileName, "var-file", "", "a file with an extra \"key=value\" variable for the Postman Environment on every line")
 	flag.Parse()
 
-	// Check if the endpoint is defined
-	if url == "" {
-		log.Fatal(`an endpoint needs to be specified with the flag "-endpoint"`)
+	// Check if the endpoint is defined, it is only needed to introspect and to execute the operations
+	if url == "" && introspectionFileName == "" {
+		log.Fatal(`an endpoint needs to be specified with the flag "-endpoint", or an introspection result with the flag "-introspection-file"`)
+	}
+	if federationSubgraph && (url == "" || introspectionFileName != "") {
+		log.Fatal(`the flag "-federation-subgraph" needs an endpoint, and can not be used with the flag "-introspection-file"`)
+	}
+	if url == "" && live {
+		log.Fatal(`an endpoint needs to be specified with the flag "-endpoint" to use the flag "-live"`)
+	}
+
+	// Collect the formats, from the flags and the config file
+	var formats []formatOutput
+	for _, f := range formatFlags {
+		format, err := parseFormat(f)
+		if err != nil {
+			log.WithError(err).Fatal("failed to parse a format")
+		}
+		formats = append(formats, format)
+	}
+	if configFileName != "" {
+		configFormats, err := readFormats(configFileName)
+		if err != nil {
+			log.WithError(err).Fatal("failed to read the config file")
+		}
+		formats = append(formats, configFormats...)
+	}
+	if len(formats) == 0 {
+		formats = []formatOutput{{Format: export.FormatPostman}}
+	}
+	if outputFileName != "" {
+		if len(formats) > 1 {
+			log.Fatal(`the flag "-output" can only be used with a single format, use "-format name=output" instead`)
+		}
+		if formats[0].Output == "" {
+			formats[0].Output = outputFileName
+		}
+	}
+
+	headers := make([]postman.Header, len(headerFlags))
+	for i, h := range headerFlags {
+		header, err := parseHeader(h)
+		if err != nil {
+			log.WithError(err).Fatal("failed to parse a header")
+		}
+		headers[i] = header
+	}
+
+	// The headers that are sent with every request, except for the content type
+	requestHeader := make(http.Header)
+	if token != "" {
+		requestHeader.Set("Authorization", "Bearer "+token)
+	}
+	for _, h := range headers {
+		requestHeader.Add(h.Key, h.Value)
 	}
 
-	// Introspect
-	log.Info("Running the GraphQL Introspection...")
-	raw, err := introspection.Introspect(url)
+	// The client that connects to the endpoint, for the introspection and the live examples
+	if insecureSkipVerify {
+		log.Warning("the certificate of the endpoint is not verified")
+	}
+	client, err := httpclient.New(httpclient.Config{
+		CAFile:             caFile,
+		CertFile:           certFile,
+		KeyFile:            keyFile,
+		ServerName:         serverName,
+		InsecureSkipVerify: insecureSkipVerify,
+		Proxy:              proxy,
+	})
 	if err != nil {
-		log.WithError(err).Fatal("could not introspect the graphql endpoint")
+		log.WithError(err).Fatal("failed to set up the connection to the endpoint")
+	}
+
+	// The extra variables of the postman environment
+	var envVariables []postman.Variable
+	if variablesFileName != "" {
+		fileVariables, err := readVariables(variablesFileName)
+		if err != nil {
+			log.WithError(err).Fatal("failed to read the variables file")
+		}
+		envVariables = append(envVariables, fileVariables...)
+	}
+
+	for _, v := range variables {
+		variable, err := parseVariable(v)
+		if err != nil {
+			log.WithError(err).Fatal("failed to parse a variable")
+		}
+		envVariables = append(envVariables, variable)
+	}
+
+	// Create the exporters before introspecting, so that unknown formats are reported right away
+	options := export.Options{
+		ID:                  postmanCollectionID,
+		Name:                postmanCollectionName,
+		URL:                 targetURL,
+		Token:               token,
+		Headers:             headers,
+		OperationNameHeader: operationNameHeader,
+		Folders:             folders,
+		BodyMode:            bodyMode,
+		SchemaVersion:       schemaVersion,
+
+		Merge:         mergeFileName,
+		Environment:   environmentFileName,
+		EnvironmentID: environmentID,
+		Variables:     envVariables,
+
+		ScriptPerOperation: scriptPerOperation,
+		K6VUs:              k6VUs,
+		K6Duration:         k6Duration,
+	}
+	exporters := make([]export.Exporter, len(formats))
+	for i, f := range formats {
+		exporter, err := export.New(f.Format, options)
+		if err != nil {
+			log.WithError(err).Fatal("failed to create an exporter")
+		}
+		exporters[i] = exporter
+		if f.Output == "" {
+			formats[i].Output = exporter.DefaultOutput()
+		}
+	}
+	if err = checkOutputs(formats, map[string]string{"the report": reportFileName, "the environment": environmentFileName}); err != nil {
+		log.WithError(err).Fatal("the outputs overlap")
+	}
+
+	// Introspect, or read the introspection result
+	var raw *introspection.Model
+	var entities []sdl.Entity
+	failure := "could not introspect the graphql endpoint"
+	switch {
+	case federationSubgraph:
+		log.Info("Fetching the SDL of the federation subgraph...")
+		failure = "could not fetch the schema of the federation subgraph"
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		var source string
+		source, err = introspection.ServiceSDL(ctx, url, introspection.Options{
+			Client:    client,
+			Transport: introspectionTransport,
+			Header:    requestHeader,
+			Timeout:   timeout,
+		})
+		stop()
+		if err == nil {
+			raw, entities, err = sdl.Parse(source)
+		}
+	case introspectionFileName == "":
+		log.Info("Running the GraphQL Introspection...")
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		raw, err = introspection.Introspect(ctx, url, introspection.Options{
+			Client:      client,
+			Transport:   introspectionTransport,
+			Header:      requestHeader,
+			Timeout:     timeout,
+			Retries:     retries,
+			RetryDelay:  retryDelay,
+			WaitUntilUp: waitUntilUp,
+			Depth:       typeDepth,
+			CacheDir:    cacheDir,
+			CacheTTL:    cacheTTL,
+			Refresh:     refresh,
+		})
+		stop()
+	case introspectionFileName == "-":
+		log.Info("Reading the GraphQL Introspection result from stdin...")
+		raw, err = introspection.Decode(os.Stdin)
+		failure = "could not read the introspection result from stdin"
+	default:
+		log.Info(`Reading the GraphQL Introspection result from "` + introspectionFileName + `"...`)
+		failure = "could not read the introspection file"
+		var file *os.File
+		if file, err = os.Open(introspectionFileName); err == nil {
+			raw, err = introspection.Decode(file)
+			file.Close()
+		}
+	}
+
+	// Recover the schema from the validation errors when introspection is disabled, unless a partial schema is good enough
+	var responseErr *introspection.ResponseError
+	if recoverSchema && !federationSubgraph && introspectionFileName == "" && errors.As(err, &responseErr) && !(responseErr.Partial && allowPartial) {
+		log.WithError(err).Warning("introspection failed, recovering the schema from the validation errors of the endpoint")
+
+		// The names to recover the schema with, the built-in ones when there is no wordlist
+		var wordlist []string
+		if wordlistFileName != "" {
+			if wordlist, err = readWordlist(wordlistFileName); err != nil {
+				log.WithError(err).Fatal("failed to read the wordlist")
+			}
+		}
+		var interval time.Duration
+		if recoverRate > 0 {
+			interval = time.Duration(float64(time.Second) / recoverRate)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		raw, err = introspection.Recover(ctx, url, introspection.RecoveryOptions{
+			Options: introspection.Options{
+				Client:    client,
+				Transport: introspectionTransport,
+				Header:    requestHeader,
+				Timeout:   timeout,
+			},
+			Wordlist:    wordlist,
+			MaxRequests: recoverMaxRequests,
+			Interval:    interval,
+		})
+		stop()
+		failure = "could not recover the schema of the graphql endpoint"
+		if err == nil {
+			log.Warning("the schema is recovered from validation errors, so it is probably incomplete")
+		}
+	}
+
+	// A schema with errors is only used when that is allowed explicitly
+	if errors.As(err, &responseErr) && responseErr.Partial && allowPartial {
+		for _, e := range responseErr.Errors {
+			log.WithField("path", e.Path).Warning("introspection error: " + e.Message)
+		}
+		log.Warning("the introspection result has errors, continuing with the partial schema")
+	} else if err != nil {
+		log.WithError(err).Fatal(failure)
 	}
 
 	log.Info("Reformatting the GraphQL Introspected models...")
@@ -210,46 +978,136 @@ func main() {
 	types = model.Types
 
 	gqlInputs := make([]postman.GqlInput, 0, len(model.Mutations)+len(model.Queries))
+	operationNames := make(map[string]bool)
 
-	// Convert the mutations
-	log.Info("Converting the mutations...")
-	for _, m := range model.Mutations {
+	// addExamples adds an example response to a GQL Input, recorded from the endpoint when execute is true.
+	// When that fails, the example of synthesize is added instead, if those are added at all.
+	addExamples := func(input *postman.GqlInput, o reformatted.Operation, execute bool, synthesize func() (*postman.Example, error)) {
+		path := input.OperationType + "." + o.Name
 
-		gqlInput, err := gqlInputFromOperation(m, "mutation")
+		if execute {
+			header := requestHeader.Clone()
+			if operationNameHeader != "" {
+				header.Set(operationNameHeader, input.OperationName)
+			}
+
+			example, err := exampleFromExecution(client, *input, url, header, timeout)
+			if err == nil {
+				input.Examples = append(input.Examples, *example)
+				return
+			}
+			if !examples {
+				warn(path, "failed to execute the operation, no example is added: "+err.Error())
+				return
+			}
+			warn(path, "failed to execute the operation, a synthesized example is added instead: "+err.Error())
+		} else if !examples {
+			return
+		}
+
+		example, err := synthesize()
 		if err != nil {
-			log.WithField("name", m.Name).WithError(err).
-				Warning("failed to convert a mutation to a GQL Input, skipping")
-			continue
+			warn(path, "failed to synthesize an example, no example is added: "+err.Error())
+			return
+		}
+		input.Examples = append(input.Examples, *example)
+	}
+
+	// convert converts an operation to a GQL Input with build, and adds how that went to the report.
+	// The name is what the operation name is made of, which is the name of the operation, unless there are several of it.
+	// The key identifies the operation across runs, the keys of the skipped operations are kept to merge with.
+	var reportOperations []report.Operation
+	var skipped []string
+	convert := func(o reformatted.Operation, operationType, name, key string, execute bool, build func(operationName string) (*postman.GqlInput, error), synthesize func() (*postman.Example, error)) {
+		warnings = nil
+		operationName := uniqueOperationName(operationType, name, operationNames)
+		reportOperation := report.Operation{
+			Name:          o.Name,
+			OperationName: operationName,
+			OperationType: operationType,
+			Status:        report.StatusGenerated,
 		}
 
-		gqlInputs = append(gqlInputs, *gqlInput)
+		gqlInput, err := build(operationName)
+		if err != nil {
+			log.WithField("name", o.Name).WithError(err).
+				Warning("failed to convert a " + operationType + " to a GQL Input, skipping")
+			reportOperation.Status = report.StatusSkipped
+			reportOperation.Error = err.Error()
+			skipped = append(skipped, key)
+		} else {
+			addExamples(gqlInput, o, execute, synthesize)
+			gqlInputs = append(gqlInputs, *gqlInput)
+		}
+
+		reportOperation.Warnings = warnings
+		if len(warnings) > 0 && reportOperation.Status == report.StatusGenerated {
+			reportOperation.Status = report.StatusPartial
+		}
+		reportOperations = append(reportOperations, reportOperation)
+	}
+
+	// Convert the mutations
+	log.Info("Converting the mutations...")
+	for _, m := range model.Mutations {
+		convert(m, "mutation", m.Name, operationKey("mutation", m.Name), live && liveMutations, func(operationName string) (*postman.GqlInput, error) {
+			return gqlInputFromOperation(m, "mutation", operationName)
+		}, func() (*postman.Example, error) {
+			return exampleFromOperation(m, "mutation")
+		})
 	}
 
 	// Convert Queries
 	log.Info("Converting the queries...")
 	for _, q := range model.Queries {
-		gqlInput, err := gqlInputFromOperation(q, "query")
-		if err != nil {
-			log.WithField("name", q.Name).WithError(err).
-				Warning("failed to convert a query to a GQL Input, skipping")
-			continue
+		convert(q, "query", q.Name, operationKey("query", q.Name), live, func(operationName string) (*postman.GqlInput, error) {
+			return gqlInputFromOperation(q, "query", operationName)
+		}, func() (*postman.Example, error) {
+			return exampleFromOperation(q, "query")
+		})
+	}
+
+	// Fetch every entity of a federation subgraph by each of its keys
+	if len(entities) > 0 {
+		log.Info("Converting the entities...")
+	}
+	for _, e := range entities {
+		o := entitiesOperation(e.Name)
+		for _, key := range e.Keys {
+			convert(o, "query", o.Name+" "+e.Name, entityKey(e.Name, key), live, func(operationName string) (*postman.GqlInput, error) {
+				return gqlInputFromEntity(e, key, operationName)
+			}, func() (*postman.Example, error) {
+				return exampleFromEntity(e)
+			})
 		}
+	}
 
-		gqlInputs = append(gqlInputs, *gqlInput)
+	// Every format is written from the same operations
+	input := export.Input{Model: model, Inputs: gqlInputs, Skipped: skipped}
+	for i, exporter := range exporters {
+		if err = exporter.Export(input, formats[i].Output); err != nil {
+			log.WithError(err).Fatal(`failed to write the "` + formats[i].Format + `" format`)
+		}
 	}
 
-	// Convert GQL Inputs to postman collection
-	log.Info("Storing the mutations and queries in a postman collection...")
-	col := postman.CreateCollection(gqlInputs, postmanCollectionID, postmanCollectionName)
-	data, err := json.MarshalIndent(col, "", "    ")
-	if err != nil {
-		log.WithError(err).Fatal("failed to encode the postman collection as json")
+	// The environment is written once, also when the collection is not one of the formats
+	if environmentFileName != "" {
+		if err = export.WriteEnvironment(options); err != nil {
+			log.WithError(err).Fatal("failed to write the environment")
+		}
 	}
 
-	if err = ioutil.WriteFile(outputFileName, data, 0644); err != nil {
-		log.WithError(err).Fatal("failed to write the postman collection to a file")
+	if reportFileName != "" {
+		log.Info(`Writing the report to "` + reportFileName + `"...`)
+		data, err := json.MarshalIndent(report.CreateReport(reportOperations, unknownScalars, model.Truncations), "", "    ")
+		if err != nil {
+			log.WithError(err).Fatal("failed to encode the report as json")
+		}
+		if err = ioutil.WriteFile(reportFileName, data, 0644); err != nil {
+			log.WithError(err).Fatal("failed to write the report to a file")
+		}
 	}
-	log.Info(`Writing the result to "` + outputFileName + `"...`)
+
 	log.Info("All done!")
-	fmt.Println()
+	fmt.Fprintln(os.Stderr)
 }